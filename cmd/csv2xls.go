@@ -1,10 +1,17 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	csv2xls "github.com/omniboost/csv2xls/lib/csv2xls"
+	"github.com/omniboost/csv2xls/lib/goxls"
 	"github.com/spf13/cobra"
 )
 
@@ -15,9 +22,10 @@ var rootCmd = &cobra.Command{
 	Long: `The csv2xls is a command line tool to convert .csv into .xls Excel formats
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		var csvFileName, xlsFileName string
+		var csvFileNames []string
+		var xlsFileName string
 		var err error
-		if csvFileName, err = cmd.Flags().GetString("csv-file-name"); err != nil {
+		if csvFileNames, err = cmd.Flags().GetStringArray("csv-file-name"); err != nil || len(csvFileNames) == 0 {
 			log.Fatal("Please specify csv-file-name parameter")
 		}
 
@@ -25,13 +33,21 @@ var rootCmd = &cobra.Command{
 			log.Fatal("Please specify xls-file-name parameter")
 		}
 
-		csvDelimiter := ";"
-		delim, err := cmd.Flags().GetString("csv-delimiter")
+		csvDelimiters := make([]string, len(csvFileNames))
+		delims, err := cmd.Flags().GetStringArray("csv-delimiter")
 		if err != nil {
 			log.Fatal(err.Error())
 		}
-		if len(delim) > 0 {
-			csvDelimiter = delim
+		if len(delims) > 1 && len(delims) != len(csvFileNames) {
+			log.Fatalf("Please specify one csv-delimiter for all csv files or one per csv file, got %d for %d files", len(delims), len(csvFileNames))
+		}
+		for i := range csvDelimiters {
+			csvDelimiters[i] = ";"
+			if len(delims) == 1 && len(delims[0]) > 0 {
+				csvDelimiters[i] = delims[0]
+			} else if len(delims) > 1 && len(delims[i]) > 0 {
+				csvDelimiters[i] = delims[i]
+			}
 		}
 
 		var title, subject, creator, keywords, description, lastModifiedBy string
@@ -55,10 +71,310 @@ var rootCmd = &cobra.Command{
 			log.Fatal(err.Error())
 		}
 
-		converter, err := csv2xls.NewCsv2XlsConverter(csvFileName, xlsFileName, csvDelimiter)
+		var dateLayouts []string
+		if dateLayouts, err = cmd.Flags().GetStringArray("date-layout"); err != nil {
+			log.Fatal(err.Error())
+		}
+		var date1904 bool
+		if date1904, err = cmd.Flags().GetBool("date-1904"); err != nil {
+			log.Fatal(err.Error())
+		}
+
+		var localeName, decimalSeparator, thousandsSeparator string
+		if localeName, err = cmd.Flags().GetString("locale"); err != nil {
+			log.Fatal(err.Error())
+		}
+		numberLocale, err := goxls.ParseLocale(localeName)
+		if err != nil {
+			log.Fatalf("Invalid locale: %s", err.Error())
+		}
+		if decimalSeparator, err = cmd.Flags().GetString("decimal-separator"); err != nil {
+			log.Fatal(err.Error())
+		}
+		if decimalSeparator != "" {
+			if numberLocale.DecimalSeparator, err = parseChar(decimalSeparator); err != nil {
+				log.Fatalf("Invalid decimal-separator: %s", err.Error())
+			}
+		}
+		if thousandsSeparator, err = cmd.Flags().GetString("thousands-separator"); err != nil {
+			log.Fatal(err.Error())
+		}
+		if thousandsSeparator != "" {
+			if numberLocale.ThousandsSeparator, err = parseChar(thousandsSeparator); err != nil {
+				log.Fatalf("Invalid thousands-separator: %s", err.Error())
+			}
+		}
+		if err = numberLocale.Validate(); err != nil {
+			log.Fatalf("Invalid number separators: %s", err.Error())
+		}
+
+		var numberFormat, dateFormat, dateTimeFormat string
+		if numberFormat, err = cmd.Flags().GetString("number-format"); err != nil {
+			log.Fatal(err.Error())
+		}
+		if dateFormat, err = cmd.Flags().GetString("date-format"); err != nil {
+			log.Fatal(err.Error())
+		}
+		if dateTimeFormat, err = cmd.Flags().GetString("datetime-format"); err != nil {
+			log.Fatal(err.Error())
+		}
+		var columnFormats []string
+		if columnFormats, err = cmd.Flags().GetStringArray("column-format"); err != nil {
+			log.Fatal(err.Error())
+		}
+
+		var fontSpec, headerFontSpec string
+		if fontSpec, err = cmd.Flags().GetString("font"); err != nil {
+			log.Fatal(err.Error())
+		}
+		if headerFontSpec, err = cmd.Flags().GetString("header-font"); err != nil {
+			log.Fatal(err.Error())
+		}
+
+		var header bool
+		if header, err = cmd.Flags().GetBool("header"); err != nil {
+			log.Fatal(err.Error())
+		}
+		var headerFill string
+		if headerFill, err = cmd.Flags().GetString("header-fill"); err != nil {
+			log.Fatal(err.Error())
+		}
+
+		var freezeRows, freezeColumns int
+		if freezeRows, err = cmd.Flags().GetInt("freeze-rows"); err != nil {
+			log.Fatal(err.Error())
+		}
+		if freezeColumns, err = cmd.Flags().GetInt("freeze-columns"); err != nil {
+			log.Fatal(err.Error())
+		}
+		if freezeRows < 0 || freezeRows > 65535 || freezeColumns < 0 || freezeColumns > 255 {
+			log.Fatal("Please specify freeze-rows between 0 and 65535 and freeze-columns between 0 and 255")
+		}
+
+		var autoFilter bool
+		if autoFilter, err = cmd.Flags().GetBool("autofilter"); err != nil {
+			log.Fatal(err.Error())
+		}
+
+		var autoColumnWidth bool
+		if autoColumnWidth, err = cmd.Flags().GetBool("auto-column-width"); err != nil {
+			log.Fatal(err.Error())
+		}
+		var maxColumnWidth int
+		if maxColumnWidth, err = cmd.Flags().GetInt("max-column-width"); err != nil {
+			log.Fatal(err.Error())
+		}
+		var columnWidths []string
+		if columnWidths, err = cmd.Flags().GetStringArray("column-width"); err != nil {
+			log.Fatal(err.Error())
+		}
+
+		var tempDir string
+		if tempDir, err = cmd.Flags().GetString("temp-dir"); err != nil {
+			log.Fatal(err.Error())
+		}
+
+		var sheetName string
+		if sheetName, err = cmd.Flags().GetString("sheet-name"); err != nil {
+			log.Fatal(err.Error())
+		}
+		if cmd.Flags().Changed("sheet-name") && len(csvFileNames) > 1 {
+			log.Fatal("The sheet-name is the name of a single csv file, name the worksheets of several csv files as name=path")
+		}
+		var inputEncodingName string
+		if inputEncodingName, err = cmd.Flags().GetString("input-encoding"); err != nil {
+			log.Fatal(err.Error())
+		}
+		inputEncoding, err := csv2xls.ParseEncoding(inputEncodingName)
+		if err != nil {
+			log.Fatalf("Invalid input-encoding: %s", err.Error())
+		}
+
+		var quoteChar, commentChar string
+		if quoteChar, err = cmd.Flags().GetString("quote-char"); err != nil {
+			log.Fatal(err.Error())
+		}
+		quote, err := parseChar(quoteChar)
+		if err != nil {
+			log.Fatalf("Invalid quote-char: %s", err.Error())
+		}
+		if commentChar, err = cmd.Flags().GetString("comment-char"); err != nil {
+			log.Fatal(err.Error())
+		}
+		comment, err := parseChar(commentChar)
+		if err != nil {
+			log.Fatalf("Invalid comment-char: %s", err.Error())
+		}
+		var backslashEscape, trimLeadingSpace bool
+		if backslashEscape, err = cmd.Flags().GetBool("backslash-escape"); err != nil {
+			log.Fatal(err.Error())
+		}
+		var escape rune
+		if backslashEscape {
+			escape = '\\'
+		}
+		if trimLeadingSpace, err = cmd.Flags().GetBool("trim-leading-space"); err != nil {
+			log.Fatal(err.Error())
+		}
+		var skipLines, skipFooterLines int
+		if skipLines, err = cmd.Flags().GetInt("skip-lines"); err != nil {
+			log.Fatal(err.Error())
+		}
+		if skipFooterLines, err = cmd.Flags().GetInt("skip-footer-lines"); err != nil {
+			log.Fatal(err.Error())
+		}
+
+		var strict, collectErrors bool
+		if strict, err = cmd.Flags().GetBool("strict"); err != nil {
+			log.Fatal(err.Error())
+		}
+		if collectErrors, err = cmd.Flags().GetBool("collect-errors"); err != nil {
+			log.Fatal(err.Error())
+		}
+
+		var splitByColumn string
+		if splitByColumn, err = cmd.Flags().GetString("split-by-column"); err != nil {
+			log.Fatal(err.Error())
+		}
+		var schemaFileName string
+		if schemaFileName, err = cmd.Flags().GetString("schema"); err != nil {
+			log.Fatal(err.Error())
+		}
+		var schema *csv2xls.Schema
+		if schemaFileName != "" {
+			if schema, err = csv2xls.LoadSchema(schemaFileName); err != nil {
+				log.Fatalf("Invalid schema: %s", err.Error())
+			}
+		}
+		var repeatHeader bool
+		if repeatHeader, err = cmd.Flags().GetBool("repeat-header"); err != nil {
+			log.Fatal(err.Error())
+		}
+		var continuationSheetName string
+		if continuationSheetName, err = cmd.Flags().GetString("continuation-sheet-name"); err != nil {
+			log.Fatal(err.Error())
+		}
+
+		var columnOverflowName string
+		if columnOverflowName, err = cmd.Flags().GetString("column-overflow"); err != nil {
+			log.Fatal(err.Error())
+		}
+		columnOverflow, err := csv2xls.ParseColumnOverflow(columnOverflowName)
 		if err != nil {
+			log.Fatalf("Invalid column-overflow: %s", err.Error())
+		}
+		// the columns beyond the first worksheet exist only when they are split over additional worksheets
+		maxColumns := goxls.MaxColumns
+		if columnOverflow == csv2xls.ColumnOverflowSplit {
+			maxColumns = goxls.MaxColumns * goxls.MaxWorksheets
+		}
+		var keyColumnSpecs []string
+		if keyColumnSpecs, err = cmd.Flags().GetStringArray("key-column"); err != nil {
 			log.Fatal(err.Error())
 		}
+		keyColumns := make([]int, 0, len(keyColumnSpecs))
+		for _, keyColumn := range keyColumnSpecs {
+			columnIdx, err := parseColumn(strings.TrimSpace(keyColumn), goxls.MaxColumns)
+			if err != nil {
+				log.Fatalf("Invalid key-column %q: %s", keyColumn, err.Error())
+			}
+			keyColumns = append(keyColumns, columnIdx)
+		}
+
+		var converter *csv2xls.Csv2XlsConverter
+		for i, csvFileSpec := range csvFileNames {
+			name, csvFileName := parseCsvFileName(csvFileSpec)
+			if name == "" && len(csvFileNames) == 1 {
+				name = sheetName
+			} else if name == "" {
+				name = strings.TrimSuffix(filepath.Base(csvFileName), filepath.Ext(csvFileName))
+			}
+
+			var sheet *csv2xls.Csv2XlsConverter
+			if i == 0 {
+				converter, err = csv2xls.NewCsv2XlsConverter(csvFileName, xlsFileName, csvDelimiters[i])
+				sheet = converter
+			} else {
+				sheet, err = converter.AddSheet(csvFileName, csvDelimiters[i])
+			}
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+
+			if len(dateLayouts) > 0 {
+				sheet.WithDateLayouts(dateLayouts...)
+			}
+
+			if headerFontSpec != "" {
+				font, err := parseFont(headerFontSpec)
+				if err != nil {
+					log.Fatalf("Invalid header-font %q: %s", headerFontSpec, err.Error())
+				}
+				sheet.WithHeaderFont(font)
+			}
+
+			if headerFill != "" {
+				color, err := goxls.ParseColor(headerFill)
+				if err != nil {
+					log.Fatalf("Invalid header-fill: %s", err.Error())
+				}
+				sheet.WithHeaderFill(color)
+			}
+
+			for _, columnWidth := range columnWidths {
+				columnIdx, value, err := parseColumnOption(columnWidth, maxColumns)
+				if err != nil {
+					log.Fatalf("Invalid column-width %q: %s", columnWidth, err.Error())
+				}
+				width, err := strconv.Atoi(value)
+				if err != nil || width < 0 || width > 255 {
+					log.Fatalf("Invalid column-width %q: width must be a number between 0 and 255", columnWidth)
+				}
+				sheet.WithColumnWidth(columnIdx, width)
+			}
+
+			for _, columnFormat := range columnFormats {
+				columnIdx, format, err := parseColumnOption(columnFormat, maxColumns)
+				if err != nil {
+					log.Fatalf("Invalid column-format %q: %s", columnFormat, err.Error())
+				}
+				sheet.WithColumnFormat(columnIdx, format)
+			}
+
+			sheet.
+				WithInputEncoding(inputEncoding).
+				WithQuote(quote).
+				WithEscape(escape).
+				WithComment(comment).
+				WithTrimLeadingSpace(trimLeadingSpace).
+				WithSkipLines(skipLines, skipFooterLines).
+				WithStrict(strict).
+				WithCollectErrors(collectErrors).
+				WithHeader(header).
+				WithFreezePanes(freezeRows, freezeColumns).
+				WithAutoFilter(autoFilter).
+				WithAutoColumnWidth(autoColumnWidth).
+				WithMaxColumnWidth(maxColumnWidth).
+				WithNumberLocale(numberLocale).
+				WithNumberFormat(numberFormat).
+				WithDateFormat(dateFormat).
+				WithDateTimeFormat(dateTimeFormat).
+				WithSheetName(name).
+				WithSplitColumn(splitByColumn).
+				WithSchema(schema).
+				WithRepeatHeader(repeatHeader).
+				WithContinuationSheetName(continuationSheetName).
+				WithColumnOverflow(columnOverflow).
+				WithKeyColumns(keyColumns...)
+		}
+
+		if fontSpec != "" {
+			font, err := parseFont(fontSpec)
+			if err != nil {
+				log.Fatalf("Invalid font %q: %s", fontSpec, err.Error())
+			}
+			converter.WithFont(font)
+		}
 
 		err = converter.
 			WithTitle(title).
@@ -67,6 +383,9 @@ var rootCmd = &cobra.Command{
 			WithKeywords(keywords).
 			WithCreator(creator).
 			WithLastModifiedBy(lastModifiedBy).
+			WithDate1904(date1904).
+			WithTempDir(tempDir).
+			WithLogger(log.Default()).
 			Convert()
 
 		if err != nil {
@@ -85,19 +404,169 @@ func Execute() {
 
 func init() {
 	// Mandatory parameter
-	rootCmd.Flags().String("csv-file-name", "", `The input csv file you want to convert`)
+	rootCmd.Flags().StringArray("csv-file-name", nil, `The input csv file you want to convert, "-" reads from stdin. Can be repeated to write one worksheet per csv file, optionally named as name=path, e.g. "Bookings=bookings.csv". Only the csv-delimiter can differ per file, the other options apply to every file`)
 	_ = rootCmd.MarkFlagRequired("csv-file-name")
 
 	// Mandatory parameter
-	rootCmd.Flags().String("xls-file-name", "", `The output xls file name that will be created`)
+	rootCmd.Flags().String("xls-file-name", "", `The output xls file name that will be created, "-" writes to stdout`)
 	_ = rootCmd.MarkFlagRequired("xls-file-name")
 
 	// Optional parameters:
-	rootCmd.Flags().String("csv-delimiter", "", `Optional. The delimiter that used in csv file. Default value is semicolon - ";"`)
+	rootCmd.Flags().StringArray("csv-delimiter", nil, `Optional. The delimiter that used in csv file, "auto" detects it from the first lines. Default value is semicolon - ";". Can be repeated to set the delimiter of every csv file in order`)
 	rootCmd.Flags().String("title", "", `Optional. The Title property of xls file`)
 	rootCmd.Flags().String("subject", "", `Optional. The Subject property of xls file`)
 	rootCmd.Flags().String("creator", "", `Optional. The Creator property of xls file`)
 	rootCmd.Flags().String("keywords", "", `Optional. The Keywords property of xls file`)
 	rootCmd.Flags().String("description", "", `Optional. The Description property of xls file`)
 	rootCmd.Flags().String("last-modified-by", "", `Optional. The LastModifiedBy property of xls file`)
+	rootCmd.Flags().StringArray("date-layout", nil, `Optional. The layout of date values in Go time format, e.g. "02.01.2006 15:04". Can be repeated. Default are ISO 8601 dates and date times`)
+	rootCmd.Flags().Bool("date-1904", false, `Optional. Use the 1904 date system instead of the 1900 one`)
+	rootCmd.Flags().String("locale", "", `Optional. The language of the numbers in the csv files, e.g. "de" for "1.234,56" or "en-US" for "1,234.56". Default are plain numbers like "1234.56"`)
+	rootCmd.Flags().String("decimal-separator", "", `Optional. The character before the fraction of numbers, overrides the one of --locale. Default value is "."`)
+	rootCmd.Flags().String("thousands-separator", "", `Optional. The character grouping the digits of numbers by three, overrides the one of --locale, e.g. "," or " "`)
+	rootCmd.Flags().String("number-format", "", `Optional. The Excel format code of numeric cells, e.g. "#,##0.00". Default value is "General"`)
+	rootCmd.Flags().String("date-format", "", `Optional. The Excel format code of date cells. Default value is "yyyy-mm-dd"`)
+	rootCmd.Flags().String("datetime-format", "", `Optional. The Excel format code of date and time cells. Default value is "yyyy-mm-dd hh:mm:ss"`)
+	rootCmd.Flags().Bool("header", false, `Optional. Treat the first row as a header: bold, with a bottom border and frozen while scrolling`)
+	rootCmd.Flags().String("header-fill", "", `Optional. The background color of the header row, e.g. "lightblue". Used with --header`)
+	rootCmd.Flags().Int("freeze-rows", 0, `Optional. The number of rows at the top that stay visible while scrolling`)
+	rootCmd.Flags().Int("freeze-columns", 0, `Optional. The number of columns on the left that stay visible while scrolling`)
+	rootCmd.Flags().Bool("autofilter", false, `Optional. Add filter drop-downs to the first row, and to the first row of the continuation worksheets with --repeat-header`)
+	rootCmd.Flags().Bool("auto-column-width", true, `Optional. Fit the column widths to the longest values`)
+	rootCmd.Flags().Int("max-column-width", csv2xls.DefaultMaxColumnWidth, `Optional. The maximum width of the fitted columns in characters`)
+	rootCmd.Flags().StringArray("column-width", nil, `Optional. The width of one column in characters as column=width, e.g. "2=40" or "B=40". Can be repeated`)
+	rootCmd.Flags().String("font", "", `Optional. The font of the cells as name:size:attributes, e.g. "Arial:10". Default value is "Calibri:11"`)
+	rootCmd.Flags().String("header-font", "", `Optional. The font of the first row as name:size:attributes, e.g. "Calibri:11:bold:red". The attributes are bold, italic, underline, strike and a color name`)
+	rootCmd.Flags().StringArray("column-format", nil, `Optional. The Excel format code of numeric and date cells in one column as column=format, e.g. "3=0%" or "C=0%". Can be repeated`)
+	rootCmd.Flags().String("sheet-name", csv2xls.DefaultSheetName, `Optional. The name of the first worksheet of a single csv file, names Excel does not allow are sanitised`)
+	rootCmd.Flags().String("input-encoding", "utf-8", `Optional. The encoding of the csv files: "utf-8", "windows-1252", "iso-8859-1", "utf-16le", "utf-16be" or "auto" to guess it. A byte order mark takes precedence`)
+	rootCmd.Flags().String("quote-char", `"`, `Optional. The character around fields holding delimiters or line breaks, an empty value turns quoting off`)
+	rootCmd.Flags().Bool("backslash-escape", false, `Optional. A backslash makes the next character be taken as it is, like \; or \"`)
+	rootCmd.Flags().String("comment-char", "", `Optional. The character starting the lines that are skipped, e.g. "#"`)
+	rootCmd.Flags().Bool("trim-leading-space", false, `Optional. Remove the white space at the start of the fields`)
+	rootCmd.Flags().Int("skip-lines", 0, `Optional. The number of lines skipped at the start of the csv files, like a title above the table`)
+	rootCmd.Flags().Int("skip-footer-lines", 0, `Optional. The number of lines skipped at the end of the csv files, like the totals below the table`)
+	rootCmd.Flags().Bool("strict", false, `Optional. Stop at rows that break the quoting rules or have another number of fields than the first row`)
+	rootCmd.Flags().Bool("collect-errors", false, `Optional. Read the whole csv file in --strict mode and list all malformed rows instead of stopping at the first one`)
+	rootCmd.Flags().String("schema", "", `Optional. A JSON file describing the columns named in the first row: name, type (text, int, decimal, date, bool, percent or currency), layout, format, width and hidden`)
+	rootCmd.Flags().String("split-by-column", "", `Optional. The header of the column the rows are grouped by, e.g. "hotel_id". The rows of every value go to a worksheet named after the value, which starts with the header row`)
+	rootCmd.Flags().Bool("repeat-header", false, `Optional. Repeat the first row at the top of the worksheets the rows beyond 65535 continue on`)
+	rootCmd.Flags().String("continuation-sheet-name", csv2xls.DefaultContinuationSheetName, `Optional. The name of the worksheets the rows beyond 65535 continue on, {name} is the name of the first worksheet, {i} the number of the continuation and {n} the number of the worksheet, e.g. "{name} ({n})"`)
+	rootCmd.Flags().String("column-overflow", "error", `Optional. The handling of rows with more than 256 columns: "error" stops the conversion, "truncate" drops the columns beyond 256 with a warning, "split" moves them to additional worksheets`)
+	rootCmd.Flags().StringArray("key-column", nil, `Optional. A column repeated at the start of the additional worksheets of --column-overflow=split, e.g. "1" or "A". Can be repeated`)
+	rootCmd.Flags().String("temp-dir", "", `Optional. The directory of the temporary files the worksheets are written to while converting. Default is the system temporary directory`)
+}
+
+// parseChar converts a one character option into a rune, an empty option is 0
+func parseChar(value string) (rune, error) {
+	if value == "" {
+		return 0, nil
+	}
+	r, size := utf8.DecodeRuneInString(value)
+	if size != len(value) {
+		return 0, fmt.Errorf("%q must be one character", value)
+	}
+
+	return r, nil
+}
+
+// parseColumnOption splits a column=value option, the column is a 1-based number or a letter reference like "C"
+// before maxColumns
+func parseColumnOption(option string, maxColumns int) (int, string, error) {
+	column, value, found := strings.Cut(option, "=")
+	if !found {
+		return 0, "", errors.New(`expected format is column=value`)
+	}
+
+	columnIdx, err := parseColumn(strings.TrimSpace(column), maxColumns)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return columnIdx, value, nil
+}
+
+// parseFont parses a name:size:attributes font specification like "Arial:10" or ":12:bold:italic:red".
+// Every part is optional, the omitted ones are taken from the default font.
+func parseFont(spec string) (goxls.Font, error) {
+	parts := strings.Split(spec, ":")
+	font := goxls.Font{Name: strings.TrimSpace(parts[0])}
+
+	for _, part := range parts[1:] {
+		part = strings.TrimSpace(part)
+		if size, err := strconv.ParseFloat(part, 64); err == nil {
+			if size < 1 || size > 409 {
+				return font, fmt.Errorf(`font size "%s" must be between 1 and 409`, part)
+			}
+			font.Size = size
+			continue
+		}
+
+		switch strings.ToLower(part) {
+		case "":
+		case "bold":
+			font.Bold = true
+		case "italic":
+			font.Italic = true
+		case "underline":
+			font.Underline = true
+		case "strike", "strikeout":
+			font.StrikeOut = true
+		default:
+			color, err := goxls.ParseColor(part)
+			if err != nil {
+				return font, err
+			}
+			font.Color = color
+		}
+	}
+
+	return font, nil
+}
+
+// parseCsvFileName splits a csv file name given as name=path into the worksheet name and the path. The name is
+// empty when there is no "=", when the part before it is a directory like in "exports/date=2024/a.csv", or when
+// the whole spec is an existing file.
+func parseCsvFileName(spec string) (string, string) {
+	name, path, ok := strings.Cut(spec, "=")
+	if !ok || strings.ContainsAny(name, `/\`) {
+		return "", spec
+	}
+	if _, err := os.Stat(spec); err == nil {
+		return "", spec
+	}
+
+	return strings.TrimSpace(name), path
+}
+
+// parseColumn converts a 1-based column number or a letter reference like "AB" into a zero-based column index,
+// the columns from maxColumns on are refused
+func parseColumn(column string, maxColumns int) (int, error) {
+	if n, err := strconv.Atoi(column); err == nil {
+		if n < 1 {
+			return 0, fmt.Errorf(`column number "%s" must be greater than 0`, column)
+		}
+		if n > maxColumns {
+			return 0, fmt.Errorf(`column "%s" is beyond the last column %s`, column, goxls.ColumnName(maxColumns-1))
+		}
+		return n - 1, nil
+	}
+
+	if column == "" {
+		return 0, errors.New("column is empty")
+	}
+
+	n := 0
+	for _, ch := range strings.ToUpper(column) {
+		if ch < 'A' || ch > 'Z' {
+			return 0, fmt.Errorf(`invalid column "%s"`, column)
+		}
+		n = n*26 + int(ch-'A'+1)
+		// checked in the loop, so long references cannot overflow
+		if n > maxColumns {
+			return 0, fmt.Errorf(`column "%s" is beyond the last column %s`, column, goxls.ColumnName(maxColumns-1))
+		}
+	}
+
+	return n - 1, nil
 }
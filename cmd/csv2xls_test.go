@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/omniboost/csv2xls/lib/goxls"
+)
+
+func TestParseColumn(t *testing.T) {
+	tests := []struct {
+		column     string
+		maxColumns int
+		want       int
+		wantErr    bool
+	}{
+		{"1", goxls.MaxColumns, 0, false},
+		{"256", goxls.MaxColumns, 255, false},
+		{"A", goxls.MaxColumns, 0, false},
+		{"c", goxls.MaxColumns, 2, false},
+		{"Z", goxls.MaxColumns, 25, false},
+		{"AA", goxls.MaxColumns, 26, false},
+		{"IV", goxls.MaxColumns, 255, false},
+		{"257", goxls.MaxColumns, 0, true},
+		{"300", goxls.MaxColumns, 0, true},
+		{"IW", goxls.MaxColumns, 0, true},
+		{"ZZZ", goxls.MaxColumns, 0, true},
+		{"ZZZZZZZZZZZZZZZZZZZZ", goxls.MaxColumns, 0, true},
+		{"99999999999999999999", goxls.MaxColumns, 0, true},
+		{"300", goxls.MaxColumns * goxls.MaxWorksheets, 299, false},
+		{"SR", goxls.MaxColumns * goxls.MaxWorksheets, 511, false},
+		{"0", goxls.MaxColumns, 0, true},
+		{"-1", goxls.MaxColumns, 0, true},
+		{"", goxls.MaxColumns, 0, true},
+		{"A1", goxls.MaxColumns, 0, true},
+		{"Ä", goxls.MaxColumns, 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseColumn(tt.column, tt.maxColumns)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("parseColumn(%q, %d) = %d, %v, want %d, error %v", tt.column, tt.maxColumns, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseColumnOption(t *testing.T) {
+	tests := []struct {
+		option    string
+		want      int
+		wantValue string
+		wantErr   bool
+	}{
+		{"3=0%", 2, "0%", false},
+		{" C =€ #,##0.00", 2, "€ #,##0.00", false},
+		{"B=", 1, "", false},
+		{"B=a=b", 1, "a=b", false},
+		{"B", 0, "", true},
+		{"=40", 0, "", true},
+		{"300=0%", 0, "", true},
+		{"ZZZ=40", 0, "", true},
+	}
+	for _, tt := range tests {
+		got, value, err := parseColumnOption(tt.option, goxls.MaxColumns)
+		if got != tt.want || value != tt.wantValue || (err != nil) != tt.wantErr {
+			t.Errorf("parseColumnOption(%q) = %d, %q, %v, want %d, %q, error %v", tt.option, got, value, err, tt.want, tt.wantValue, tt.wantErr)
+		}
+	}
+}
+
+func TestParseFont(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    goxls.Font
+		wantErr bool
+	}{
+		{"Arial", goxls.Font{Name: "Arial"}, false},
+		{"Arial:10", goxls.Font{Name: "Arial", Size: 10}, false},
+		{" Times New Roman : 12.5 ", goxls.Font{Name: "Times New Roman", Size: 12.5}, false},
+		{":12:bold:italic:red", goxls.Font{Size: 12, Bold: true, Italic: true, Color: goxls.ColorRed}, false},
+		{"::Underline:strike", goxls.Font{Underline: true, StrikeOut: true}, false},
+		{"::strikeout", goxls.Font{StrikeOut: true}, false},
+		{"", goxls.Font{}, false},
+		{"Arial:0.5", goxls.Font{}, true},
+		{"Arial:410", goxls.Font{}, true},
+		{"Arial:fancy", goxls.Font{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseFont(tt.spec)
+		if (err != nil) != tt.wantErr || err == nil && got != tt.want {
+			t.Errorf("parseFont(%q) = %+v, %v, want %+v, error %v", tt.spec, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseChar(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    rune
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"'", '\'', false},
+		{"#", '#', false},
+		{"€", '€', false},
+		{"\t", '\t', false},
+		{"ab", 0, true},
+		{"€€", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseChar(tt.value)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("parseChar(%q) = %q, %v, want %q, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseCsvFileName(t *testing.T) {
+	// an existing file named with a "=" in the working directory is read as a path
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	if err := os.WriteFile("x=y.csv", nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		spec     string
+		wantName string
+		wantPath string
+	}{
+		{"a.csv", "", "a.csv"},
+		{"-", "", "-"},
+		{"Bookings=bookings.csv", "Bookings", "bookings.csv"},
+		{" Bookings 2024 =data/bookings.csv", "Bookings 2024", "data/bookings.csv"},
+		{"a=b=c.csv", "a", "b=c.csv"},
+		{"=a.csv", "", "a.csv"},
+		{"exports/date=2024/a.csv", "", "exports/date=2024/a.csv"},
+		{`exports\date=2024\a.csv`, "", `exports\date=2024\a.csv`},
+		{"x=y.csv", "", "x=y.csv"},
+		{"x=z.csv", "x", "z.csv"},
+	}
+	for _, tt := range tests {
+		name, path := parseCsvFileName(tt.spec)
+		if name != tt.wantName || path != tt.wantPath {
+			t.Errorf("parseCsvFileName(%q) = %q, %q, want %q, %q", tt.spec, name, path, tt.wantName, tt.wantPath)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package csv2xls
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omniboost/csv2xls/lib/goxls"
+)
+
+// ColumnOverflow is the handling of the rows with more columns than a worksheet holds
+type ColumnOverflow int
+
+const (
+	// ColumnOverflowError stops the conversion with goxls.ErrTooManyColumns, it is the default
+	ColumnOverflowError ColumnOverflow = iota
+	// ColumnOverflowTruncate drops the columns beyond goxls.MaxColumns and logs a warning
+	ColumnOverflowTruncate
+	// ColumnOverflowSplit moves the columns beyond goxls.MaxColumns to additional worksheets,
+	// every additional worksheet starts with the key columns
+	ColumnOverflowSplit
+)
+
+// ParseColumnOverflow parses the name of a column overflow policy: "error", "truncate" or "split"
+func ParseColumnOverflow(name string) (ColumnOverflow, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "error":
+		return ColumnOverflowError, nil
+	case "truncate":
+		return ColumnOverflowTruncate, nil
+	case "split":
+		return ColumnOverflowSplit, nil
+	}
+
+	return ColumnOverflowError, fmt.Errorf(`unknown column overflow "%s", expected error, truncate or split`, name)
+}
+
+// columnSplitter distributes the columns of wide rows over worksheets. The first worksheet gets the first
+// goxls.MaxColumns columns, every next one the key columns followed by as many of the remaining columns as fit.
+type columnSplitter struct {
+	keyColumns []int
+}
+
+// newColumnSplitter ...
+func newColumnSplitter(keyColumns []int) (columnSplitter, error) {
+	if len(keyColumns) >= goxls.MaxColumns {
+		return columnSplitter{}, fmt.Errorf("too many key columns, at most %d are allowed", goxls.MaxColumns-1)
+	}
+	for _, columnIdx := range keyColumns {
+		if columnIdx < 0 || columnIdx >= goxls.MaxColumns {
+			return columnSplitter{}, fmt.Errorf("key column %s must be one of the first %d columns", goxls.ColumnName(columnIdx), goxls.MaxColumns)
+		}
+	}
+
+	return columnSplitter{keyColumns: keyColumns}, nil
+}
+
+// groupWidth is the number of non-key columns in the additional worksheets
+func (s columnSplitter) groupWidth() int {
+	return goxls.MaxColumns - len(s.keyColumns)
+}
+
+// groups returns the number of worksheets a row with the given number of columns is spread over
+func (s columnSplitter) groups(columns int) int {
+	if columns <= goxls.MaxColumns {
+		return 1
+	}
+
+	return 1 + (columns-goxls.MaxColumns+s.groupWidth()-1)/s.groupWidth()
+}
+
+// firstColumn returns the index of the first non-key csv column of the group
+func (s columnSplitter) firstColumn(group int) int {
+	if group == 0 {
+		return 0
+	}
+
+	return goxls.MaxColumns + (group-1)*s.groupWidth()
+}
+
+// columns returns the indexes of the csv columns of the group, in the order of the worksheet columns
+func (s columnSplitter) columns(group int) []int {
+	if group == 0 {
+		columns := make([]int, goxls.MaxColumns)
+		for i := range columns {
+			columns[i] = i
+		}
+		return columns
+	}
+
+	columns := append([]int(nil), s.keyColumns...)
+	first := s.firstColumn(group)
+	for i := 0; i < s.groupWidth(); i++ {
+		columns = append(columns, first+i)
+	}
+
+	return columns
+}
+
+// cells appends the cells of the row that belong to the group to dst
+func (s columnSplitter) cells(dst []string, row []string, group int) []string {
+	if group == 0 {
+		return append(dst, row[:min(len(row), goxls.MaxColumns)]...)
+	}
+
+	for _, columnIdx := range s.keyColumns {
+		cell := ""
+		if columnIdx < len(row) {
+			cell = row[columnIdx]
+		}
+		dst = append(dst, cell)
+	}
+
+	first := s.firstColumn(group)
+	if first < len(row) {
+		dst = append(dst, row[first:min(len(row), first+s.groupWidth())]...)
+	}
+
+	return dst
+}
+
+// remapColumns returns the values of the csv columns keyed by their index in a worksheet with the given columns
+func remapColumns[V any](values map[int]V, columns []int) map[int]V {
+	if values == nil {
+		return nil
+	}
+
+	remapped := make(map[int]V)
+	for worksheetIdx, columnIdx := range columns {
+		if value, ok := values[columnIdx]; ok {
+			remapped[worksheetIdx] = value
+		}
+	}
+
+	return remapped
+}
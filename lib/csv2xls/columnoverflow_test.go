@@ -0,0 +1,158 @@
+package csv2xls
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/omniboost/csv2xls/lib/goxls"
+)
+
+// wideCsv returns a csv document with a header and a row of the given number of columns, followed by a short row
+func wideCsv(columns int) string {
+	header := make([]string, columns)
+	row := make([]string, columns)
+	for i := range header {
+		header[i] = "h" + strconv.Itoa(i)
+		row[i] = "v" + strconv.Itoa(i)
+	}
+
+	return strings.Join(header, ";") + "\n" + strings.Join(row, ";") + "\nshort;row;x\n"
+}
+
+// convertTestCsv converts the csv document and reads back the worksheets
+func convertTestCsv(t *testing.T, c *Csv2XlsConverter, document string) []testSheet {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := c.ConvertReader(strings.NewReader(document), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	return readTestXLS(t, buf.Bytes())
+}
+
+func TestColumnOverflowSplit(t *testing.T) {
+	tests := []struct {
+		name       string
+		keyColumns []int
+		wantSheets []string
+	}{
+		{"no key columns", nil, []string{"worksheet", "worksheet col IW", "worksheet col SS"}},
+		{"one key column", []int{0}, []string{"worksheet", "worksheet col IW", "worksheet col SR"}},
+		{"two key columns", []int{2, 0}, []string{"worksheet", "worksheet col IW", "worksheet col SQ"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCsv2XlsConverter("", "", ";")
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.WithColumnOverflow(ColumnOverflowSplit).WithKeyColumns(tt.keyColumns...)
+			sheets := convertTestCsv(t, c, wideCsv(600))
+
+			if names := testSheetNames(sheets); strings.Join(names, ",") != strings.Join(tt.wantSheets, ",") {
+				t.Fatalf("worksheets %q, want %q", names, tt.wantSheets)
+			}
+
+			// every csv column is on exactly one worksheet after the key columns
+			seen := make(map[string]int)
+			for i, sheet := range sheets {
+				if len(sheet.rows) < 2 || len(sheet.rows) > 3 {
+					t.Fatalf("worksheet %q has %d rows, want 3", sheet.name, len(sheet.rows))
+				}
+				header, row := sheet.rows[0], sheet.rows[1]
+				if len(header) > goxls.MaxColumns || len(header) != len(row) {
+					t.Fatalf("worksheet %q has %d header and %d row cells", sheet.name, len(header), len(row))
+				}
+				keys := 0
+				if i > 0 {
+					keys = len(tt.keyColumns)
+				}
+				for j, name := range header {
+					if "v"+name[1:] != row[j] {
+						t.Errorf("worksheet %q cell %s2 is %q under %q", sheet.name, goxls.ColumnName(j), row[j], name)
+					}
+					if j < keys {
+						if want := "h" + strconv.Itoa(tt.keyColumns[j]); name != want {
+							t.Errorf("worksheet %q key column %s is %q, want %q", sheet.name, goxls.ColumnName(j), name, want)
+						}
+						continue
+					}
+					seen[name]++
+				}
+
+				// the short row has its key columns on every worksheet, it is empty without key columns
+				var short []string
+				if len(sheet.rows) > 2 {
+					short = sheet.rows[2]
+				}
+				wantShort := []string{"short", "row", "x"}
+				if i > 0 {
+					wantShort = nil
+					for _, columnIdx := range tt.keyColumns {
+						wantShort = append(wantShort, []string{"short", "row", "x"}[columnIdx])
+					}
+				}
+				if strings.Join(short, ";") != strings.Join(wantShort, ";") {
+					t.Errorf("worksheet %q short row is %q, want %q", sheet.name, short, wantShort)
+				}
+			}
+			for i := 0; i < 600; i++ {
+				if name := "h" + strconv.Itoa(i); seen[name] != 1 {
+					t.Errorf("column %s is on %d worksheets", name, seen[name])
+				}
+			}
+		})
+	}
+}
+
+func TestColumnOverflowTruncate(t *testing.T) {
+	c, err := NewCsv2XlsConverter("", "", ";")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logs bytes.Buffer
+	c.WithColumnOverflow(ColumnOverflowTruncate).WithLogger(log.New(&logs, "", 0))
+	sheets := convertTestCsv(t, c, wideCsv(300))
+
+	if len(sheets) != 1 || len(sheets[0].rows) != 3 {
+		t.Fatalf("worksheets %q, want one with 3 rows", testSheetNames(sheets))
+	}
+	for _, row := range sheets[0].rows[:2] {
+		if len(row) != goxls.MaxColumns || row[goxls.MaxColumns-1][1:] != "255" {
+			t.Errorf("row has %d cells ending with %q, want %d ending with column 255", len(row), row[len(row)-1], goxls.MaxColumns)
+		}
+	}
+	if want := "Row 1 has 300 columns, the columns after IV are dropped\n"; logs.String() != want {
+		t.Errorf("logged %q, want %q", logs.String(), want)
+	}
+}
+
+func TestColumnOverflowError(t *testing.T) {
+	c, err := NewCsv2XlsConverter("", "", ";")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	err = c.ConvertReader(strings.NewReader(wideCsv(goxls.MaxColumns+1)), &buf)
+	if !errors.Is(err, goxls.ErrTooManyColumns) {
+		t.Errorf("ConvertReader() error %v, want %v", err, goxls.ErrTooManyColumns)
+	}
+
+	// the last column of a worksheet is not an overflow
+	if err := c.ConvertReader(strings.NewReader(wideCsv(goxls.MaxColumns)), &buf); err != nil {
+		t.Errorf("ConvertReader() of %d columns: %v", goxls.MaxColumns, err)
+	}
+}
+
+func TestNewColumnSplitter(t *testing.T) {
+	if _, err := newColumnSplitter([]int{goxls.MaxColumns}); err == nil {
+		t.Error("newColumnSplitter() accepts a key column beyond the first worksheet")
+	}
+	if _, err := newColumnSplitter(make([]int, goxls.MaxColumns)); err == nil {
+		t.Error("newColumnSplitter() accepts a key column for every column")
+	}
+}
@@ -0,0 +1,84 @@
+package csv2xls
+
+import (
+	"strings"
+)
+
+const (
+	// defaultColumnWidth is the width of the columns in characters when auto fitting is off
+	defaultColumnWidth = 10
+	// DefaultMaxColumnWidth limits the auto fitted column width
+	DefaultMaxColumnWidth = 50
+	// maxColumnWidth is the widest column Excel supports
+	maxColumnWidth = 255
+	// columnWidthPadding is added to the longest value, so it is not glued to the cell border
+	columnWidthPadding = 2
+)
+
+// columnWidthFitter tracks the width of every column that has a value longer than the default width
+// as the rows arrive
+type columnWidthFitter struct {
+	maxWidth     int
+	columnWidths map[int]int
+}
+
+// newColumnWidthFitter ...
+func newColumnWidthFitter(maxWidth int) *columnWidthFitter {
+	if maxWidth <= 0 || maxWidth > maxColumnWidth {
+		maxWidth = maxColumnWidth
+	}
+
+	return &columnWidthFitter{
+		maxWidth:     maxWidth,
+		columnWidths: make(map[int]int),
+	}
+}
+
+// addRow widens the columns with values longer than the widths so far
+func (f *columnWidthFitter) addRow(row []string) {
+	for columnIdx, value := range row {
+		width := displayWidth(value) + columnWidthPadding
+		if width <= defaultColumnWidth || width <= f.columnWidths[columnIdx] {
+			continue
+		}
+		f.columnWidths[columnIdx] = min(width, f.maxWidth)
+	}
+}
+
+// displayWidth returns the width of the longest line of the value in characters, wide East Asian runes take two
+func displayWidth(value string) int {
+	width := 0
+	for _, line := range strings.Split(value, "\n") {
+		lineWidth := 0
+		for _, r := range line {
+			lineWidth++
+			if isWideRune(r) {
+				lineWidth++
+			}
+		}
+		width = max(width, lineWidth)
+	}
+
+	return width
+}
+
+// isWideRune reports whether the rune is displayed in double width, like CJK ideographs, Hangul and fullwidth forms
+func isWideRune(r rune) bool {
+	switch {
+	case r < 0x1100:
+		return false
+	case r <= 0x115F, // Hangul Jamo
+		r >= 0x2E80 && r <= 0xA4CF && r != 0x303F, // CJK radicals ... Yi
+		r >= 0xAC00 && r <= 0xD7A3,                // Hangul syllables
+		r >= 0xF900 && r <= 0xFAFF,                // CJK compatibility ideographs
+		r >= 0xFE30 && r <= 0xFE4F,                // CJK compatibility forms
+		r >= 0xFF00 && r <= 0xFF60,                // Fullwidth forms
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F, // Emoji
+		r >= 0x1F900 && r <= 0x1F9FF,
+		r >= 0x20000 && r <= 0x3FFFD: // CJK extensions
+		return true
+	}
+
+	return false
+}
@@ -7,9 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log"
 	"math"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -17,6 +17,14 @@ import (
 	"github.com/omniboost/csv2xls/lib/goxls"
 )
 
+// DefaultDateLayouts are the date and time layouts recognised when no other layouts are configured
+var DefaultDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+}
+
 const (
 	olePpsTypeRoot   = 5
 	olePpsTypeDir    = 1
@@ -26,17 +34,67 @@ const (
 	olePpsSize       = 0x80
 )
 
+// rowsPerWorksheet is the number of rows after which the next worksheet is started
+const rowsPerWorksheet = 65535
+
+// StdStream is the file name that stands for stdin as the csv file and for stdout as the xls file
+const StdStream = "-"
+
 // Csv2XlsConverter ...
 type Csv2XlsConverter struct {
 	csvFileName    string
 	xlsFileName    string
-	csvDelimiter   rune
+	csvDelimiter   string
 	title          string
 	subject        string
 	creator        string
 	keywords       string
 	description    string
 	lastModifiedBy string
+	dateLayouts    []string
+	date1904       bool
+	numberLocale   goxls.NumberLocale
+	numberFormat   string
+	dateFormat     string
+	dateTimeFormat string
+	columnStyles   map[int]goxls.Style
+	font           goxls.Font
+	headerFont     *goxls.Font
+	header         bool
+	headerFill     uint8
+	freezeRows     int
+	freezeColumns  int
+	autoFilter     bool
+
+	autoColumnWidth bool
+	maxColumnWidth  int
+	columnWidths    map[int]int
+
+	tempDir string
+
+	columnOverflow ColumnOverflow
+	keyColumns     []int
+	logger         *log.Logger
+
+	sheetName             string
+	repeatHeader          bool
+	continuationSheetName string
+	splitColumn           string
+	inputEncoding         Encoding
+	strict                bool
+	collectErrors         bool
+	quote                 rune
+	escape                rune
+	comment               rune
+	trimLeadingSpace      bool
+	skipLines             int
+	skipFooterLines       int
+	schema                *Schema
+
+	// sheets are the csv files written to the worksheets after the ones of this converter,
+	// workbook is the converter a sheet was added to
+	sheets   []*Csv2XlsConverter
+	workbook *Csv2XlsConverter
 }
 
 type dataSectionItem struct {
@@ -50,112 +108,240 @@ type dataSectionItem struct {
 
 // NewCsv2XlsConverter ...
 func NewCsv2XlsConverter(csvFileName string, xlsFileName string, csvDelimiter string) (*Csv2XlsConverter, error) {
-	if utf8.RuneCountInString(csvDelimiter) > 1 {
-		return nil, errors.New("csv delimiter must be one character string")
+	if strings.ContainsAny(csvDelimiter, "\r\n") {
+		return nil, errors.New("csv delimiter must not hold line breaks")
 	}
 
-	csvDelimiterDecoded, _ := utf8.DecodeRuneInString(csvDelimiter)
+	// the empty delimiter is detected when the csv document is read
+	switch {
+	case csvDelimiter == "":
+		csvDelimiter = ";"
+	case strings.EqualFold(csvDelimiter, AutoDelimiter):
+		csvDelimiter = ""
+	}
 
 	return &Csv2XlsConverter{
-		csvFileName:  csvFileName,
-		xlsFileName:  xlsFileName,
-		csvDelimiter: csvDelimiterDecoded,
+		csvFileName:     csvFileName,
+		xlsFileName:     xlsFileName,
+		csvDelimiter:    csvDelimiter,
+		dateLayouts:     DefaultDateLayouts,
+		autoColumnWidth: true,
+		maxColumnWidth:  DefaultMaxColumnWidth,
+
+		sheetName:             DefaultSheetName,
+		continuationSheetName: DefaultContinuationSheetName,
+		quote:                 '"',
 	}, nil
 }
 
-// Convert....
+// Convert reads the csv file and writes the xls file. The file name StdStream reads from stdin or writes to stdout.
 func (c *Csv2XlsConverter) Convert() error {
-	sc, err := GetStringCollectionFromCSVFile(c.csvFileName, c.csvDelimiter)
+	csvReader, err := c.openCsv()
 	if err != nil {
 		return err
 	}
+	defer csvReader.Close()
 
-	buf, err := c.FromStringCollectionToXLS(&sc)
-	if err != nil {
-		return err
+	if c.xlsFileName == StdStream {
+		w := bufio.NewWriter(os.Stdout)
+		if err := c.ConvertReader(csvReader, w); err != nil {
+			return err
+		}
+		return w.Flush()
 	}
 
 	f, err := os.Create(c.xlsFileName)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	// Issue a `Sync` to flush writes to stable storage.
-	err3 := f.Sync()
-	if err3 != nil {
+	err = c.convertToFile(csvReader, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// do not leave a broken xls file behind
+		os.Remove(c.xlsFileName)
 		return err
 	}
 
+	return nil
+}
+
+// convertToFile writes the xls document to f and flushes it to stable storage, the caller closes f
+func (c *Csv2XlsConverter) convertToFile(csvReader io.Reader, f *os.File) error {
 	w := bufio.NewWriter(f)
+	if err := c.ConvertReader(csvReader, w); err != nil {
+		return err
+	}
 
-	_, err2 := w.Write(buf)
-	if err2 != nil {
+	// Use `Flush` to ensure all buffered operations have been applied to the file
+	if err := w.Flush(); err != nil {
 		return err
 	}
 
-	// Use `Flush` to ensure all buffered operations have
-	err4 := w.Flush()
-	if err4 != nil {
+	// Issue a `Sync` to flush writes to stable storage.
+	return f.Sync()
+}
+
+// ConvertTo reads the csv file and writes the xls document to w. The file name StdStream reads from stdin.
+func (c *Csv2XlsConverter) ConvertTo(w io.Writer) error {
+	csvReader, err := c.openCsv()
+	if err != nil {
 		return err
 	}
+	defer csvReader.Close()
 
-	return nil
+	return c.ConvertReader(csvReader, w)
 }
 
-// From CSV Reader to XLS ...
-func (c *Csv2XlsConverter) FromStringCollectionToXLS(stringCollection *goxls.StringCollection) ([]byte, error) {
-	var CreatedAtInt int64 = time.Now().Unix()
-	var ModifiedAtInt int64 = time.Now().Unix()
+// openCsv opens the csv file, or stdin when the file name is StdStream
+func (c *Csv2XlsConverter) openCsv() (io.ReadCloser, error) {
+	if c.csvFileName == StdStream {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	f, err := os.Open(c.csvFileName)
+	if err != nil {
+		return nil, fmt.Errorf(`cannot read csv file "%s"`, c.csvFileName)
+	}
+
+	return f, nil
+}
+
+// ConvertReader reads the csv document from r and writes the xls document to w.
+// The rows are converted as they are read, so memory holds the shared strings table only.
+func (c *Csv2XlsConverter) ConvertReader(r io.Reader, w io.Writer) error {
+	nextRow, err := c.newRowReader(r)
+	if err != nil {
+		return err
+	}
 
-	columnWidths := make(map[int]int, 0)
-	//columnWidths[1] = 40 // parameter todo
+	return c.writeXLS(nextRow, newStringCollection(), w)
+}
 
-	wsArr := make([]goxls.Worksheet, 0)
-	n := 0
-	for i := 0; i < len(stringCollection.StringGrid); i += 65535 {
-		wsName := "worksheet"
-		if n > 0 {
-			wsName += strconv.Itoa(n)
+// newRowReader returns the function reading the rows of the csv document r. The document is transcoded to UTF-8,
+// see WithInputEncoding, and its delimiter is detected when it was given as AutoDelimiter.
+// The slice of the rows is reused.
+func (c *Csv2XlsConverter) newRowReader(r io.Reader) (func() ([]string, error), error) {
+	r, encoding, err := decodeReader(r, c.inputEncoding)
+	if err != nil {
+		return nil, err
+	}
+	if encoding != c.inputEncoding && c.inputEncoding != EncodingUTF8 && c.inputEncoding != EncodingAuto {
+		c.logf("The csv file %s starts with a byte order mark of %s, which is used instead of %s", c.csvFileName, encoding, c.inputEncoding)
+	}
+
+	if c.skipLines > 0 || c.skipFooterLines > 0 {
+		r = newLineSkipper(r, c.skipLines, c.skipFooterLines)
+	}
+
+	dialect := csvDialect{
+		delimiter:        c.csvDelimiter,
+		quote:            c.quote,
+		escape:           c.escape,
+		comment:          c.comment,
+		trimLeadingSpace: c.trimLeadingSpace,
+	}
+	if dialect.delimiter == "" {
+		var detected rune
+		var ok bool
+		if r, detected, ok, err = detectDelimiter(r, dialect); err != nil {
+			return nil, err
 		}
+		if ok {
+			c.logf("The csv file %s is delimited by %q", c.csvFileName, detected)
+		} else {
+			detected = ';'
+			c.logf("The delimiter of the csv file %s is not detected, %q is used", c.csvFileName, detected)
+		}
+		dialect.delimiter = string(detected)
+	}
+
+	var raw *rawRecorder
+	if c.strict {
+		raw = &rawRecorder{r: r}
+		r = raw
+	}
 
-		last := i + 65535
-		if last > len(stringCollection.StringGrid) {
-			last = len(stringCollection.StringGrid)
+	var records recordReader
+	if dialect.isStandard() {
+		comma, _ := utf8.DecodeRuneInString(dialect.delimiter)
+		csvReader := newCsvReader(r, comma)
+		csvReader.ReuseRecord = true
+		csvReader.Comment = c.comment
+		csvReader.TrimLeadingSpace = c.trimLeadingSpace
+		if c.strict {
+			csvReader.LazyQuotes = false
+			csvReader.FieldsPerRecord = 0
 		}
-		wsArr = append(wsArr, goxls.Worksheet{
-			Name:         wsName,
-			Grid:         stringCollection.StringGrid[i:last],
-			ColumnWidths: columnWidths,
-		})
-		n++
+		records = csvReader
+	} else if records, err = newDialectReader(r, dialect, c.strict); err != nil {
+		return nil, err
 	}
 
-	worksheetDatas := make([]string, 0)
-	worksheetNames := make([]string, 0)
-	for _, ws := range wsArr {
-		worksheetDatas = append(worksheetDatas, ws.GetData(stringCollection))
-		worksheetNames = append(worksheetNames, ws.Name)
+	if !c.strict {
+		return records.Read, nil
 	}
+	strict := &strictReader{
+		csv:          records,
+		raw:          raw,
+		file:         c.csvFileName,
+		skippedLines: c.skipLines,
+		collect:      c.collectErrors,
+	}
+
+	return strict.Read, nil
+}
 
-	worksheetSizes := make([]int, 0)
-	for _, wsd := range worksheetDatas {
-		worksheetSizes = append(worksheetSizes, len(wsd))
+// From CSV Reader to XLS ...
+func (c *Csv2XlsConverter) FromStringCollectionToXLS(stringCollection *goxls.StringCollection) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := c.FromStringCollectionToWriter(stringCollection, buf); err != nil {
+		return nil, err
 	}
 
-	workbook := goxls.Workbook{
-		WorksheetSizes:   worksheetSizes,
-		WorksheetNames:   worksheetNames,
-		StringCollection: stringCollection,
+	return buf.Bytes(), nil
+}
+
+// FromStringCollectionToWriter writes the xls document of the string collection to w
+func (c *Csv2XlsConverter) FromStringCollectionToWriter(stringCollection *goxls.StringCollection, w io.Writer) error {
+	rowIdx := 0
+	nextRow := func() ([]string, error) {
+		if rowIdx >= len(stringCollection.StringGrid) {
+			return nil, io.EOF
+		}
+		rowIdx++
+		return stringCollection.StringGrid[rowIdx-1], nil
 	}
 
-	var data strings.Builder
-	data.WriteString(workbook.GetWorksheetSizesData())
+	return c.writeXLS(nextRow, stringCollection, w)
+}
+
+// writeXLS writes the xls document of the rows returned by nextRow until io.EOF, followed by the worksheets
+// of the csv files added by AddSheet. The worksheets are spilled to temporary files, then the OLE container
+// is assembled from them.
+func (c *Csv2XlsConverter) writeXLS(nextRow func() ([]string, error), stringCollection *goxls.StringCollection, w io.Writer) error {
+	ww := newWorkbookWriter(c, stringCollection)
+	defer ww.close()
 
-	for _, wsd := range worksheetDatas {
-		data.WriteString(wsd)
+	if err := ww.writeSheet(c, nextRow); err != nil {
+		return err
+	}
+	for _, sheet := range c.sheets {
+		if err := ww.writeCsvSheet(sheet); err != nil {
+			return err
+		}
 	}
 
+	return ww.writeTo(w)
+}
+
+// writeOle writes the OLE container with the workbook stream of the given size and the summary information
+func (c *Csv2XlsConverter) writeOle(workbookStream io.Reader, workbookSize int, w io.Writer) error {
+	var CreatedAtInt int64 = time.Now().Unix()
+	var ModifiedAtInt int64 = time.Now().Unix()
+
 	rootPps := goxls.PPS{
 		No:         0,
 		Name:       goxls.AsciiToUcs("Root Entry"),
@@ -175,9 +361,19 @@ func (c *Csv2XlsConverter) FromStringCollectionToXLS(stringCollection *goxls.Str
 		PrevPps:    2,
 		NextPps:    3,
 		DirPps:     0xFFFFFFFF,
-		Data:       data.String(),
-		Size:       0,
+		Data:       "",
+		Size:       uint32(workbookSize),
 		StartBlock: 0,
+		Stream:     workbookStream,
+	}
+	if workbookSize < oleDataSizeSmall {
+		// small streams are stored in the small blocks of the root entry
+		data, err := io.ReadAll(workbookStream)
+		if err != nil {
+			return err
+		}
+		workbookPps.Data = string(data)
+		workbookPps.Stream = nil
 	}
 
 	// TODO
@@ -200,24 +396,34 @@ func (c *Csv2XlsConverter) FromStringCollectionToXLS(stringCollection *goxls.Str
 
 	iSBDcnt, iBBcnt, iPPScnt := calcSize(aList) // change types to uint32 TODO
 
-	// Content of this buffer is result xls file
-	resultBuffer := new(bytes.Buffer)
+	// The xls file is written straight to w, the first write error is returned at the end
+	resultWriter := &stickyErrWriter{w: bufio.NewWriter(w)}
 
-	saveHeader(resultBuffer, iSBDcnt, iBBcnt, iPPScnt)
+	head := goxls.NewRecordWriter(1024)
+	saveHeader(head, iSBDcnt, iBBcnt, iPPScnt)
 
-	smallData := makeSmallData(resultBuffer, aList)
+	smallData := makeSmallData(head, aList)
 	aList[0].Data = smallData
+	_, _ = head.WriteTo(resultWriter)
 
 	// Write BB
-	saveBigData(resultBuffer, iSBDcnt, aList)
+	if err := saveBigData(resultWriter, iSBDcnt, aList); err != nil {
+		return err
+	}
 
 	// Write PPS
-	savePps(resultBuffer, aList)
+	tail := goxls.NewRecordWriter(int(iBBcnt/128+2) * 512)
+	savePps(tail, aList)
 
 	// Write Big Block Depot and BDList and Adding Header information
-	saveBbd(resultBuffer, iSBDcnt, iBBcnt, iPPScnt)
+	saveBbd(tail, iSBDcnt, iBBcnt, iPPScnt)
+	_, _ = tail.WriteTo(resultWriter)
 
-	return resultBuffer.Bytes(), nil
+	if resultWriter.err != nil {
+		return resultWriter.err
+	}
+
+	return resultWriter.w.Flush()
 }
 
 // WithTitle ...
@@ -256,7 +462,272 @@ func (c *Csv2XlsConverter) WithLastModifiedBy(lastModifiedBy string) *Csv2XlsCon
 	return c
 }
 
-func saveBbd(buffer *bytes.Buffer, iSbdSize, iBsize, iPpsCnt uint32) {
+// WithDateLayouts sets the time layouts of the values stored as dates, see time.Parse.
+// Without layouts date detection is disabled.
+func (c *Csv2XlsConverter) WithDateLayouts(layouts ...string) *Csv2XlsConverter {
+	c.dateLayouts = layouts
+	return c
+}
+
+// WithDate1904 switches the workbook to the 1904 date system
+func (c *Csv2XlsConverter) WithDate1904(date1904 bool) *Csv2XlsConverter {
+	c.date1904 = date1904
+	return c
+}
+
+// WithNumberFormat sets the format code of the numeric cells, e.g. "#,##0.00"
+func (c *Csv2XlsConverter) WithNumberFormat(format string) *Csv2XlsConverter {
+	c.numberFormat = format
+	return c
+}
+
+// WithNumberLocale sets the way the numbers are written, like "1.234,56" with goxls.ParseLocale("de").
+// Grouped digits, currency symbols, a minus after the number and parentheses around a negative number
+// are recognised then, the default recognises plain numbers like "1234.56" only.
+func (c *Csv2XlsConverter) WithNumberLocale(locale goxls.NumberLocale) *Csv2XlsConverter {
+	c.numberLocale = locale
+	return c
+}
+
+// WithDateFormat sets the format code of the date cells, e.g. "dd-mm-yyyy"
+func (c *Csv2XlsConverter) WithDateFormat(format string) *Csv2XlsConverter {
+	c.dateFormat = format
+	return c
+}
+
+// WithDateTimeFormat sets the format code of the date and time cells, e.g. "dd-mm-yyyy hh:mm"
+func (c *Csv2XlsConverter) WithDateTimeFormat(format string) *Csv2XlsConverter {
+	c.dateTimeFormat = format
+	return c
+}
+
+// WithColumnFormat sets the format code of the numeric and date cells in the column with zero-based index columnIdx
+func (c *Csv2XlsConverter) WithColumnFormat(columnIdx int, format string) *Csv2XlsConverter {
+	if c.columnStyles == nil {
+		c.columnStyles = make(map[int]goxls.Style)
+	}
+	style := c.columnStyles[columnIdx]
+	style.NumberFormat = format
+	c.columnStyles[columnIdx] = style
+	return c
+}
+
+// WithFont sets the default font of the workbook
+func (c *Csv2XlsConverter) WithFont(font goxls.Font) *Csv2XlsConverter {
+	c.font = font
+	return c
+}
+
+// WithHeaderFont sets the font of the first row
+func (c *Csv2XlsConverter) WithHeaderFont(font goxls.Font) *Csv2XlsConverter {
+	c.headerFont = &font
+	return c
+}
+
+// WithHeader treats the first row as a header: it is bold, has a bottom border and stays visible while scrolling.
+// The font can be changed with WithHeaderFont.
+func (c *Csv2XlsConverter) WithHeader(header bool) *Csv2XlsConverter {
+	c.header = header
+	return c
+}
+
+// WithHeaderFill sets the palette index of the header background color, see goxls.ParseColor
+func (c *Csv2XlsConverter) WithHeaderFill(color uint8) *Csv2XlsConverter {
+	c.headerFill = color
+	return c
+}
+
+// WithFreezePanes keeps the first rows and columns visible while scrolling.
+// The rows are frozen in the first worksheet, the columns in every worksheet.
+func (c *Csv2XlsConverter) WithFreezePanes(rows int, columns int) *Csv2XlsConverter {
+	c.freezeRows = rows
+	c.freezeColumns = columns
+	return c
+}
+
+// WithAutoFilter adds filter drop-downs to the first row of the first worksheet. The worksheets the rows beyond
+// 65535 continue on get them only with WithRepeatHeader, their first row is not the header otherwise.
+func (c *Csv2XlsConverter) WithAutoFilter(autoFilter bool) *Csv2XlsConverter {
+	c.autoFilter = autoFilter
+	return c
+}
+
+// autoFilterRange returns the range from the first row to the last row and the widest column, nil when there are no cells
+func autoFilterRange(rows int, columns int) *goxls.CellRange {
+	if rows == 0 || columns == 0 {
+		return nil
+	}
+
+	return &goxls.CellRange{
+		FirstRow:    0,
+		LastRow:     rows - 1,
+		FirstColumn: 0,
+		LastColumn:  columns - 1,
+	}
+}
+
+// WithAutoColumnWidth turns fitting of the column widths to the longest values on or off, it is on by default
+func (c *Csv2XlsConverter) WithAutoColumnWidth(autoColumnWidth bool) *Csv2XlsConverter {
+	c.autoColumnWidth = autoColumnWidth
+	return c
+}
+
+// WithMaxColumnWidth limits the fitted column widths, in characters
+func (c *Csv2XlsConverter) WithMaxColumnWidth(width int) *Csv2XlsConverter {
+	c.maxColumnWidth = width
+	return c
+}
+
+// WithColumnWidth sets the width in characters of the column with zero-based index columnIdx
+func (c *Csv2XlsConverter) WithColumnWidth(columnIdx int, width int) *Csv2XlsConverter {
+	if c.columnWidths == nil {
+		c.columnWidths = make(map[int]int)
+	}
+	c.columnWidths[columnIdx] = width
+	return c
+}
+
+// AddSheet adds the csv file csvFileName to the workbook, its worksheets follow the worksheets of the csv
+// files added before. The returned converter holds the settings of these worksheets: the With methods of
+// the worksheets apply, the document properties, the font, the date system and the logger are taken from c.
+func (c *Csv2XlsConverter) AddSheet(csvFileName string, csvDelimiter string) (*Csv2XlsConverter, error) {
+	sheet, err := NewCsv2XlsConverter(csvFileName, "", csvDelimiter)
+	if err != nil {
+		return nil, err
+	}
+	sheet.workbook = c
+	c.sheets = append(c.sheets, sheet)
+
+	return sheet, nil
+}
+
+// WithSheetName sets the name of the first worksheet, the continuation worksheets are named after it.
+// Names Excel does not allow are sanitised, see goxls.SanitizeSheetName, and duplicates are numbered.
+func (c *Csv2XlsConverter) WithSheetName(name string) *Csv2XlsConverter {
+	c.sheetName = name
+	return c
+}
+
+// WithRepeatHeader sets whether the first row is repeated at the top of the worksheets the rows
+// beyond the limit of a worksheet continue on, these worksheets then hold one data row less
+func (c *Csv2XlsConverter) WithRepeatHeader(repeatHeader bool) *Csv2XlsConverter {
+	c.repeatHeader = repeatHeader
+	return c
+}
+
+// WithContinuationSheetName sets the name of the worksheets the rows beyond the limit of a worksheet
+// continue on, see DefaultContinuationSheetName for the placeholders
+func (c *Csv2XlsConverter) WithContinuationSheetName(format string) *Csv2XlsConverter {
+	c.continuationSheetName = format
+	return c
+}
+
+// WithInputEncoding sets the character encoding of the csv document, the default is UTF-8.
+// A byte order mark takes precedence over it, a UTF-8 one is removed so it does not end up in the first cell.
+func (c *Csv2XlsConverter) WithInputEncoding(encoding Encoding) *Csv2XlsConverter {
+	c.inputEncoding = encoding
+	return c
+}
+
+// WithQuote sets the character around the fields holding delimiters or line breaks, the default is
+// the double quote. A doubled quote character in a quoted field stands for itself, 0 turns quoting off.
+func (c *Csv2XlsConverter) WithQuote(quote rune) *Csv2XlsConverter {
+	c.quote = quote
+	return c
+}
+
+// WithEscape sets the character before a character that is taken as it is, like \; or \", 0 is no escaping
+func (c *Csv2XlsConverter) WithEscape(escape rune) *Csv2XlsConverter {
+	c.escape = escape
+	return c
+}
+
+// WithComment sets the character starting the lines that are skipped, like #, 0 is no comments
+func (c *Csv2XlsConverter) WithComment(comment rune) *Csv2XlsConverter {
+	c.comment = comment
+	return c
+}
+
+// WithTrimLeadingSpace sets whether the white space at the start of the fields is removed
+func (c *Csv2XlsConverter) WithTrimLeadingSpace(trimLeadingSpace bool) *Csv2XlsConverter {
+	c.trimLeadingSpace = trimLeadingSpace
+	return c
+}
+
+// WithSkipLines sets the number of lines skipped at the start and at the end of the csv document,
+// like a title above and the totals below the table
+func (c *Csv2XlsConverter) WithSkipLines(leading int, trailing int) *Csv2XlsConverter {
+	c.skipLines = leading
+	c.skipFooterLines = trailing
+	return c
+}
+
+// WithStrict sets whether the csv document must follow the quoting rules and have the number of fields of
+// the first row in every row, a malformed row stops the conversion with a CsvError
+func (c *Csv2XlsConverter) WithStrict(strict bool) *Csv2XlsConverter {
+	c.strict = strict
+	return c
+}
+
+// WithCollectErrors sets whether the strict mode reads the whole csv document and stops the conversion
+// with CsvErrors listing all the malformed rows instead of stopping at the first one
+func (c *Csv2XlsConverter) WithCollectErrors(collectErrors bool) *Csv2XlsConverter {
+	c.collectErrors = collectErrors
+	return c
+}
+
+// WithSplitColumn sets the header of the column the rows are grouped by, the rows of every value of
+// the column are written to a worksheet named after the value, which starts with the header row.
+// The worksheet name set by WithSheetName is not used then.
+func (c *Csv2XlsConverter) WithSplitColumn(header string) *Csv2XlsConverter {
+	c.splitColumn = strings.TrimSpace(header)
+	return c
+}
+
+// WithSchema sets the types, number formats, widths and visibility of the columns named in the first row.
+// The cells that do not match the type of their column are stored as text and reported to the logger.
+func (c *Csv2XlsConverter) WithSchema(schema *Schema) *Csv2XlsConverter {
+	c.schema = schema
+	return c
+}
+
+// WithColumnOverflow sets the handling of the rows with more columns than a worksheet holds.
+// ColumnOverflowSplit repeats the key columns, see WithKeyColumns, in the additional worksheets.
+func (c *Csv2XlsConverter) WithColumnOverflow(columnOverflow ColumnOverflow) *Csv2XlsConverter {
+	c.columnOverflow = columnOverflow
+	return c
+}
+
+// WithKeyColumns sets the zero-based indexes of the columns repeated at the start of the worksheets
+// holding the columns beyond the limit of a worksheet
+func (c *Csv2XlsConverter) WithKeyColumns(columns ...int) *Csv2XlsConverter {
+	c.keyColumns = columns
+	return c
+}
+
+// WithLogger sets the logger of the warnings, they are not reported without a logger
+func (c *Csv2XlsConverter) WithLogger(logger *log.Logger) *Csv2XlsConverter {
+	c.logger = logger
+	return c
+}
+
+// logf reports a warning to the logger
+func (c *Csv2XlsConverter) logf(format string, args ...interface{}) {
+	if c.workbook != nil {
+		c.workbook.logf(format, args...)
+	} else if c.logger != nil {
+		c.logger.Printf(format, args...)
+	}
+}
+
+// WithTempDir sets the directory of the temporary files the worksheets are spilled to while converting,
+// the default directory for temporary files is used when it is empty
+func (c *Csv2XlsConverter) WithTempDir(dir string) *Csv2XlsConverter {
+	c.tempDir = dir
+	return c
+}
+
+func saveBbd(buffer *goxls.RecordWriter, iSbdSize, iBsize, iPpsCnt uint32) {
 	// Calculate Basic Setting
 	var iBbCnt uint32 = 512 / oleLongIntSize
 	var i1stBdL uint32 = (512 - 0x4C) / oleLongIntSize
@@ -296,39 +767,39 @@ func saveBbd(buffer *bytes.Buffer, iSbdSize, iBsize, iPpsCnt uint32) {
 	if iSbdSize > 0 {
 		var i uint32
 		for i = 0; i < (iSbdSize - 1); i++ {
-			goxls.PutVar(buffer, i+1)
+			buffer.PutUint32(i + 1)
 		}
-		goxls.PutVar(buffer, []byte("\xFE\xFF\xFF\xFF")) // uint32(-2)
+		buffer.PutUint32(0xFFFFFFFE) // uint32(-2)
 	}
 
 	// Set for B
 	var i uint32
 	for i = 0; i < (iBsize - 1); i++ {
-		goxls.PutVar(buffer, i+iSbdSize+1)
+		buffer.PutUint32(i + iSbdSize + 1)
 	}
-	goxls.PutVar(buffer, []byte("\xFE\xFF\xFF\xFF"))
+	buffer.PutUint32(0xFFFFFFFE)
 
 	// Set for PPS
 	for i = 0; i < (iPpsCnt - 1); i++ {
-		goxls.PutVar(buffer, i+iSbdSize+iBsize+1)
+		buffer.PutUint32(i + iSbdSize + iBsize + 1)
 	}
-	goxls.PutVar(buffer, []byte("\xFE\xFF\xFF\xFF"))
+	buffer.PutUint32(0xFFFFFFFE)
 
 	// Set for BBD itself ( 0xFFFFFFFD : BBD)
 	for i = 0; i < iBdCnt; i++ {
-		goxls.PutVar(buffer, uint32(0xFFFFFFFD))
+		buffer.PutUint32(0xFFFFFFFD)
 	}
 
 	// Set for ExtraBDList
 	for i = 0; i < iBdExL; i++ {
-		goxls.PutVar(buffer, uint32(0xFFFFFFFC))
+		buffer.PutUint32(0xFFFFFFFC)
 	}
 
 	// Adjust for Block
 	if (iAllW+iBdCnt)%iBbCnt > 0 {
 		iBlock := iBbCnt - ((iAllW + iBdCnt) % iBbCnt)
 		for i = 0; i < iBlock; i++ {
-			goxls.PutVar(buffer, []byte("\xFF\xFF\xFF\xFF"))
+			buffer.PutUint32(0xFFFFFFFF)
 		}
 	}
 
@@ -339,44 +810,58 @@ func saveBbd(buffer *bytes.Buffer, iSbdSize, iBsize, iPpsCnt uint32) {
 			if iN >= (iBbCnt - 1) {
 				iN = 0
 				iNb++
-				goxls.PutVar(buffer, iAll+iBdCnt+iNb)
+				buffer.PutUint32(iAll + iBdCnt + iNb)
 			}
-			goxls.PutVar(buffer, iBsize+iSbdSize+iPpsCnt+i)
+			buffer.PutUint32(iBsize + iSbdSize + iPpsCnt + i)
 			iN++
 		}
 		if (iBdCnt-i1stBdL)%(iBbCnt-1) > 0 {
 			iB := (iBbCnt - 1) - ((iBdCnt - i1stBdL) % (iBbCnt - 1))
 			for i = 0; i < iB; i++ {
-				goxls.PutVar(buffer, []byte("\xFF\xFF\xFF\xFF"))
+				buffer.PutUint32(0xFFFFFFFF)
 			}
 		}
-		goxls.PutVar(buffer, []byte("\xFE\xFF\xFF\xFF"))
+		buffer.PutUint32(0xFFFFFFFE)
 	}
 }
 
-func savePps(buffer *bytes.Buffer, raList []goxls.PPS) {
+func savePps(buffer *goxls.RecordWriter, raList []goxls.PPS) {
 	// Save each PPS WK
 	for _, pps := range raList {
-		goxls.PutVar(buffer, []byte(pps.GetPpsWk())) // maybe it'll be better to change return type to []byte
+		buffer.PutString(pps.GetPpsWk())
 	}
 	// Adjust for Block
 	iCnt := len(raList)
 	iBCnt := 512 / olePpsSize
 	if iCnt%iBCnt > 0 {
-		goxls.PutVar(buffer, []byte(strings.Repeat("\x00", (iBCnt-(iCnt%iBCnt))*olePpsSize)))
+		buffer.PutString(strings.Repeat("\x00", (iBCnt-(iCnt%iBCnt))*olePpsSize))
 	}
 }
 
-func saveBigData(buffer *bytes.Buffer, iStBlk uint32, raList []goxls.PPS) {
+func saveBigData(buffer io.Writer, iStBlk uint32, raList []goxls.PPS) error {
 	// cycle through PPS's
 	for i := range raList {
 		if raList[i].PpsType != olePpsTypeDir {
-			raList[i].Size = uint32(len(raList[i].Data))
+			if raList[i].Stream == nil {
+				raList[i].Size = uint32(len(raList[i].Data))
+			}
 			if raList[i].Size >= oleDataSizeSmall || (raList[i].PpsType == olePpsTypeRoot && len(raList[i].Data) != 0) {
-				goxls.PutVar(buffer, []byte(raList[i].Data))
+				if raList[i].Stream != nil {
+					n, err := io.Copy(buffer, raList[i].Stream)
+					if err != nil {
+						return err
+					}
+					if n != int64(raList[i].Size) {
+						return fmt.Errorf("stream size %d differs from the expected %d", n, raList[i].Size)
+					}
+				} else if _, err := io.WriteString(buffer, raList[i].Data); err != nil {
+					return err
+				}
 
 				if raList[i].Size%512 > 0 {
-					goxls.PutVar(buffer, []byte(strings.Repeat("\x00", 512-int(raList[i].Size)%512)))
+					if _, err := io.WriteString(buffer, strings.Repeat("\x00", 512-int(raList[i].Size)%512)); err != nil {
+						return err
+					}
 				}
 				// Set For PPS
 				raList[i].StartBlock = iStBlk
@@ -387,9 +872,11 @@ func saveBigData(buffer *bytes.Buffer, iStBlk uint32, raList []goxls.PPS) {
 			}
 		}
 	}
+
+	return nil
 }
 
-func makeSmallData(buffer *bytes.Buffer, raList []goxls.PPS) string {
+func makeSmallData(buffer *goxls.RecordWriter, raList []goxls.PPS) string {
 	var smallData strings.Builder
 	var iSmBlk uint32 = 0
 
@@ -408,9 +895,9 @@ func makeSmallData(buffer *bytes.Buffer, raList []goxls.PPS) string {
 				jB := iSmbCnt - 1
 				var j uint32
 				for j = 0; j < jB; j++ {
-					goxls.PutVar(buffer, j+iSmBlk+1)
+					buffer.PutUint32(j + iSmBlk + 1)
 				}
-				goxls.PutVar(buffer, []byte("\xFE\xFF\xFF\xFF")) // uint32(-2)
+				buffer.PutUint32(0xFFFFFFFE) // uint32(-2)
 
 				smallData.WriteString(raList[i].Data)
 				if raList[i].Size%64 > 0 {
@@ -428,14 +915,14 @@ func makeSmallData(buffer *bytes.Buffer, raList []goxls.PPS) string {
 		iB := iSbCnt - (iSmBlk % iSbCnt)
 		var i uint32
 		for i = 0; i < iB; i++ {
-			goxls.PutVar(buffer, []byte("\xFF\xFF\xFF\xFF"))
+			buffer.PutUint32(0xFFFFFFFF)
 		}
 	}
 
 	return smallData.String()
 }
 
-func saveHeader(buffer *bytes.Buffer, iSBDcnt, iBBcnt, iPPScnt uint32) {
+func saveHeader(buffer *goxls.RecordWriter, iSBDcnt, iBBcnt, iPPScnt uint32) {
 	// Calculate Basic Setting
 	var iBlCnt uint32 = 512 / oleLongIntSize
 	var i1stBdL uint32 = (512 - 0x4C) / oleLongIntSize
@@ -472,52 +959,37 @@ func saveHeader(buffer *bytes.Buffer, iSBDcnt, iBBcnt, iPPScnt uint32) {
 	}
 
 	// Save Header
-	goxls.PutVar(buffer,
-		[]byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1"),
-		[]byte("\x00\x00\x00\x00"),
-		[]byte("\x00\x00\x00\x00"),
-		[]byte("\x00\x00\x00\x00"),
-		[]byte("\x00\x00\x00\x00"),
-		uint16(0x3b),
-		uint16(0x03),
-		[]byte("\xFE\xFF"), // uint16(-2),
-		uint16(9),
-		uint16(6),
-		uint16(0),
-		[]byte("\x00\x00\x00\x00"),
-		[]byte("\x00\x00\x00\x00"),
-		iBdCnt,
-		iBBcnt+iSBDcnt,
-		uint32(0),
-		uint32(0x1000),
-	)
+	buffer.PutString("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")
+	buffer.PutUint32(0, 0, 0, 0)
+	buffer.PutUint16(0x3b, 0x03, 0xFFFE, 9, 6, 0)
+	buffer.PutUint32(0, 0, iBdCnt, iBBcnt+iSBDcnt, 0, 0x1000)
 	if iSBDcnt > 0 {
-		goxls.PutVar(buffer, uint32(0))
+		buffer.PutUint32(0)
 	} else {
-		goxls.PutVar(buffer, []byte("\xFE\xFF\xFF\xFF"))
+		buffer.PutUint32(0xFFFFFFFE)
 	}
-	goxls.PutVar(buffer, iSBDcnt)
+	buffer.PutUint32(iSBDcnt)
 
 	// Extra BDList Start, Count
 	if iBdCnt < i1stBdL {
-		goxls.PutVar(buffer,
-			[]byte("\xFE\xFF\xFF\xFF"), // Extra BDList Start
-			uint32(0),                  // Extra BDList Count
+		buffer.PutUint32(
+			0xFFFFFFFE, // Extra BDList Start
+			0,          // Extra BDList Count
 		)
 	} else {
-		goxls.PutVar(buffer, iAll+iBdCnt, iBdExL)
+		buffer.PutUint32(iAll+iBdCnt, iBdExL)
 	}
 
 	// BDList
 	var i uint32
 	for i = 0; i < i1stBdL && i < iBdCnt; i++ {
-		goxls.PutVar(buffer, iAll+i)
+		buffer.PutUint32(iAll + i)
 	}
 	if i < i1stBdL {
 		jB := i1stBdL - i
 		var j uint32
 		for j = 0; j < jB; j++ {
-			goxls.PutVar(buffer, []byte("\xFF\xFF\xFF\xFF"))
+			buffer.PutUint32(0xFFFFFFFF)
 		}
 	}
 }
@@ -529,7 +1001,9 @@ func calcSize(aList []goxls.PPS) (uint32, uint32, uint32) {
 	iCount := len(aList)
 	for i := 0; i < iCount; i++ {
 		if aList[i].PpsType == olePpsTypeFile {
-			aList[i].Size = uint32(len(aList[i].Data))
+			if aList[i].Stream == nil {
+				aList[i].Size = uint32(len(aList[i].Data))
+			}
 
 			if aList[i].Size < oleDataSizeSmall {
 				iSBcnt += int(math.Floor(float64(aList[i].Size) / 64))
@@ -566,25 +1040,25 @@ func calcSize(aList []goxls.PPS) (uint32, uint32, uint32) {
 }
 
 func getSummaryInformation(title, subject, creator, keywords, description, lastModifiedBy string, created, modified int64) string {
-	buffer := new(bytes.Buffer)
+	buffer := goxls.NewRecordWriter(512)
 
 	// offset: 0; size: 2; must be 0xFE 0xFF (UTF-16 LE byte order mark)
-	goxls.PutVar(buffer, uint16(0xFFFE))
+	buffer.PutUint16(0xFFFE)
 	// offset: 2; size: 2;
-	goxls.PutVar(buffer, uint16(0x0000))
+	buffer.PutUint16(0x0000)
 	// offset: 4; size: 2; OS version
-	goxls.PutVar(buffer, uint16(0x0106))
+	buffer.PutUint16(0x0106)
 	// offset: 6; size: 2; OS indicator
-	goxls.PutVar(buffer, uint16(0x0002))
+	buffer.PutUint16(0x0002)
 	// offset: 8; size: 16
-	goxls.PutVar(buffer, uint32(0x00), uint32(0x00), uint32(0x00), uint32(0x00))
+	buffer.PutUint32(0x00, 0x00, 0x00, 0x00)
 	// offset: 24; size: 4; section count
-	goxls.PutVar(buffer, uint32(0x0001))
+	buffer.PutUint32(0x0001)
 
 	// offset: 28; size: 16; first section's class id: 02 d5 cd d5 9c 2e 1b 10 93 97 08 00 2b 2c f9 ae
-	goxls.PutVar(buffer, uint16(0x85E0), uint16(0xF29F), uint16(0x4FF9), uint16(0x1068), uint16(0x91AB), uint16(0x0008), uint16(0x272B), uint16(0xD9B3))
+	buffer.PutUint16(0x85E0, 0xF29F, 0x4FF9, 0x1068, 0x91AB, 0x0008, 0x272B, 0xD9B3)
 	// offset: 44; size: 4; offset of the start
-	goxls.PutVar(buffer, uint32(0x30))
+	buffer.PutUint32(0x30)
 
 	var dataSectionNumProps uint32 = 0
 	dataSections := make([]dataSectionItem, 0)
@@ -645,23 +1119,23 @@ func getSummaryInformation(title, subject, creator, keywords, description, lastM
 	dataSections = append(dataSections, dataSectionItem{0x13, 0, 0x03, 0x00, "", 0})
 	dataSectionNumProps++
 
-	dataSectionSummary := new(bytes.Buffer)
-	dataSectionContent := new(bytes.Buffer)
+	dataSectionSummary := goxls.NewRecordWriter(128)
+	dataSectionContent := goxls.NewRecordWriter(512)
 	dataSectionContentOffset := 8 + dataSectionNumProps*8
 
 	for _, dataSection := range dataSections {
 		// Summary
-		goxls.PutVar(dataSectionSummary, dataSection.summary)
+		dataSectionSummary.PutUint32(dataSection.summary)
 		// Offset
-		goxls.PutVar(dataSectionSummary, dataSectionContentOffset)
+		dataSectionSummary.PutUint32(dataSectionContentOffset)
 		// DataType
-		goxls.PutVar(dataSectionContent, dataSection.sType)
+		dataSectionContent.PutUint32(dataSection.sType)
 		// Data
 		if dataSection.sType == 0x02 { // 2 byte signed integer
-			goxls.PutVar(dataSectionContent, dataSection.dataInt)
+			dataSectionContent.PutUint32(dataSection.dataInt)
 			dataSectionContentOffset += 8
 		} else if dataSection.sType == 0x03 { // 4 byte signed integer
-			goxls.PutVar(dataSectionContent, dataSection.dataInt)
+			dataSectionContent.PutUint32(dataSection.dataInt)
 			dataSectionContentOffset += 8
 		} else if dataSection.sType == 0x1E { // null-terminated string prepended by dword string length
 			// Null-terminated string
@@ -675,12 +1149,12 @@ func getSummaryInformation(title, subject, creator, keywords, description, lastM
 
 			dataSection.dataString = dataSection.dataString + strings.Repeat("\x00", int(dataSection.dataLength)-len(dataSection.dataString))
 
-			goxls.PutVar(dataSectionContent, dataSection.dataLength)
-			goxls.PutVar(dataSectionContent, []byte(dataSection.dataString))
+			dataSectionContent.PutUint32(dataSection.dataLength)
+			dataSectionContent.PutString(dataSection.dataString)
 
 			dataSectionContentOffset += 8 + uint32(len(dataSection.dataString))
 		} else if dataSection.sType == 0x40 { // Filetime (64-bit value representing the number of 100-nanosecond intervals since January 1, 1601)
-			goxls.PutVar(dataSectionContent, []byte(dataSection.dataString))
+			dataSectionContent.PutString(dataSection.dataString)
 			dataSectionContentOffset += 4 + 8
 		}
 		// Data Type Not Used at the moment
@@ -690,16 +1164,16 @@ func getSummaryInformation(title, subject, creator, keywords, description, lastM
 	// section header
 	// offset: $secOffset; size: 4; section length
 	//         + x  Size of the content (summary + content)
-	goxls.PutVar(buffer, dataSectionContentOffset)
+	buffer.PutUint32(dataSectionContentOffset)
 
 	// offset: $secOffset+4; size: 4; property count
-	goxls.PutVar(buffer, dataSectionNumProps)
+	buffer.PutUint32(dataSectionNumProps)
 
 	// Section Summary
-	goxls.PutVar(buffer, dataSectionSummary.Bytes())
+	buffer.PutBytes(dataSectionSummary.Bytes())
 
 	// Section Content
-	goxls.PutVar(buffer, dataSectionContent.Bytes())
+	buffer.PutBytes(dataSectionContent.Bytes())
 
 	return buffer.String()
 }
@@ -716,18 +1190,12 @@ func GetStringCollectionFromCSVFile(csvFileName string, delimiter rune) (goxls.S
 }
 
 func GetStringCollectionFromCSVReader(reader io.Reader, delimiter rune) (goxls.StringCollection, error) {
-	sc := goxls.StringCollection{
-		StringGrid:   make([][]string, 0),
-		StringMap:    make(map[string]int, 0),
-		StringList:   make([]string, 0),
-		StringTotal:  0,
-		StringUnique: 0,
+	sc := *newStringCollection()
+	reader, _, err := decodeReader(reader, EncodingUTF8)
+	if err != nil {
+		return sc, err
 	}
-
-	r := csv.NewReader(reader)
-	r.FieldsPerRecord = -1
-	r.Comma = delimiter
-	r.LazyQuotes = true
+	r := newCsvReader(reader, delimiter)
 
 	for {
 		record, err := r.Read()
@@ -745,3 +1213,45 @@ func GetStringCollectionFromCSVReader(reader io.Reader, delimiter rune) (goxls.S
 
 	return sc, nil
 }
+
+// newStringCollection ...
+func newStringCollection() *goxls.StringCollection {
+	return &goxls.StringCollection{
+		StringGrid:   make([][]string, 0),
+		StringMap:    make(map[string]int, 0),
+		StringList:   make([]string, 0),
+		StringTotal:  0,
+		StringUnique: 0,
+	}
+}
+
+// newCsvReader ...
+func newCsvReader(reader io.Reader, delimiter rune) *csv.Reader {
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.Comma = delimiter
+	r.LazyQuotes = true
+
+	return r
+}
+
+// stickyErrWriter keeps the first write error and drops the writes after it,
+// so a failed write can be checked once at the end instead of after every write
+type stickyErrWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (sw *stickyErrWriter) Write(p []byte) (int, error) {
+	if sw.err != nil {
+		return len(p), nil
+	}
+
+	n, err := sw.w.Write(p)
+	if err != nil {
+		sw.err = err
+		return len(p), nil
+	}
+
+	return n, nil
+}
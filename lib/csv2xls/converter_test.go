@@ -0,0 +1,142 @@
+package csv2xls
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// errAfterReader fails with err once the document is read
+type errAfterReader struct {
+	r   io.Reader
+	err error
+}
+
+func (r *errAfterReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if err == io.EOF {
+		return n, r.err
+	}
+	return n, err
+}
+
+// testCsv returns a csv document with a header and the given number of rows
+func testCsv(rows int) string {
+	var sb strings.Builder
+	sb.WriteString("id;name\n")
+	for i := 1; i <= rows; i++ {
+		sb.WriteString(strconv.Itoa(i) + ";name " + strconv.Itoa(i) + "\n")
+	}
+	return sb.String()
+}
+
+func TestConvertReaderStreaming(t *testing.T) {
+	rows := rowsPerWorksheet + 100
+	document := testCsv(rows)
+	tempDir := t.TempDir()
+
+	c, err := NewCsv2XlsConverter("", "", ";")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := c.WithTempDir(tempDir).ConvertReader(strings.NewReader(document), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// the BOUNDSHEET offsets are checked to point at the BOF of every worksheet
+	sheets := readTestXLS(t, buf.Bytes())
+	if len(sheets) != 2 || sheets[0].name != "worksheet" || sheets[1].name != "worksheet1" {
+		t.Fatalf("worksheets %v, want worksheet and worksheet1", testSheetNames(sheets))
+	}
+	if len(sheets[0].rows) != rowsPerWorksheet || len(sheets[1].rows) != rows+1-rowsPerWorksheet {
+		t.Fatalf("worksheets have %d and %d rows, want %d and %d", len(sheets[0].rows), len(sheets[1].rows), rowsPerWorksheet, rows+1-rowsPerWorksheet)
+	}
+	for i, row := range append(sheets[0].rows, sheets[1].rows...) {
+		want := []string{"id", "name"}
+		if i > 0 {
+			want = []string{strconv.Itoa(i), "name " + strconv.Itoa(i)}
+		}
+		if strings.Join(row, ";") != strings.Join(want, ";") {
+			t.Fatalf("row %d is %q, want %q", i, row, want)
+		}
+	}
+
+	// the streamed workbook is the one of the string collection
+	sc, err := GetStringCollectionFromCSVReader(strings.NewReader(document), ';')
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := c.FromStringCollectionToXLS(&sc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(readTestWorkbookStream(t, buf.Bytes()), readTestWorkbookStream(t, want)) {
+		t.Error("the workbook stream differs from the one of FromStringCollectionToXLS")
+	}
+
+	assertEmptyDir(t, tempDir)
+}
+
+func TestConvertReaderFailureRemovesTempFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	c, err := NewCsv2XlsConverter("", "", ";")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the read error comes after the first worksheet is spilled to its temporary file
+	readErr := errors.New("connection reset")
+	r := &errAfterReader{r: strings.NewReader(testCsv(rowsPerWorksheet + 10)), err: readErr}
+	if err := c.WithTempDir(tempDir).ConvertReader(r, io.Discard); !errors.Is(err, readErr) {
+		t.Fatalf("ConvertReader() error %v, want %v", err, readErr)
+	}
+
+	assertEmptyDir(t, tempDir)
+}
+
+func TestConvertFailureRemovesXlsFile(t *testing.T) {
+	dir := t.TempDir()
+	csvFileName := filepath.Join(dir, "test.csv")
+	xlsFileName := filepath.Join(dir, "test.xls")
+	if err := os.WriteFile(csvFileName, []byte("a;b\n1;2\n3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := NewCsv2XlsConverter(csvFileName, xlsFileName, ";")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.WithStrict(true).Convert(); err == nil {
+		t.Fatal("Convert() of an invalid csv file succeeded")
+	}
+	if _, err := os.Stat(xlsFileName); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("the broken xls file is left behind: %v", err)
+	}
+}
+
+// assertEmptyDir fails when the directory holds files
+func assertEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		t.Errorf("file %s is left behind in %s", entry.Name(), dir)
+	}
+}
+
+// testSheetNames returns the names of the worksheets
+func testSheetNames(sheets []testSheet) []string {
+	names := make([]string, len(sheets))
+	for i, sheet := range sheets {
+		names[i] = sheet.name
+	}
+	return names
+}
@@ -0,0 +1,104 @@
+package csv2xls
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"io"
+)
+
+// AutoDelimiter is the csv delimiter that is detected from the first lines of the csv document
+const AutoDelimiter = "auto"
+
+// delimiterCandidates are the delimiters AutoDelimiter chooses from, in the order of preference on a tie
+var delimiterCandidates = []rune{';', ',', '\t', '|'}
+
+// delimiterSampleLines is the number of lines AutoDelimiter looks at
+const delimiterSampleLines = 100
+
+// delimiterSampleSize is the maximum number of bytes AutoDelimiter looks at
+const delimiterSampleSize = 64 * 1024
+
+// detectDelimiter returns the delimiter of the csv document read by r, and false when no candidate splits
+// the lines into more than one field. The lines are read with the quote, escape and comment characters of
+// the dialect, its delimiter is ignored. The returned reader reads the document from the start.
+func detectDelimiter(r io.Reader, dialect csvDialect) (io.Reader, rune, bool, error) {
+	src := bufio.NewReaderSize(r, delimiterSampleSize)
+	sample, err := src.Peek(delimiterSampleSize)
+	if err != nil && err != io.EOF {
+		return nil, 0, false, err
+	}
+	if err == nil {
+		// do not look at the line the sample ends in
+		if i := bytes.LastIndexByte(sample, '\n'); i >= 0 {
+			sample = sample[:i+1]
+		}
+	}
+
+	delimiter, ok := scoreDelimiters(sample, dialect)
+
+	return src, delimiter, ok, nil
+}
+
+// scoreDelimiters parses the sample with every candidate and chooses the one that splits most lines into
+// the same number of fields, more fields win a tie. A candidate breaking the quoting rules is scored by the
+// lines before the error and loses half its score. The comment lines are skipped, the candidates that are the
+// quote, escape or comment character of the dialect are not tried.
+func scoreDelimiters(sample []byte, dialect csvDialect) (rune, bool) {
+	var best rune
+	bestScore, bestFields := 0.0, 0
+	for _, candidate := range delimiterCandidates {
+		dialect.delimiter = string(candidate)
+		if dialect.validate() != nil {
+			continue
+		}
+		var r recordReader
+		if dialect.isStandard() {
+			csvReader := csv.NewReader(bytes.NewReader(sample))
+			csvReader.Comma = candidate
+			csvReader.Comment = dialect.comment
+			csvReader.FieldsPerRecord = -1
+			csvReader.ReuseRecord = true
+			r = csvReader
+		} else {
+			// the dialect reader is lenient, a candidate is not scored by quoting errors then
+			r, _ = newDialectReader(bytes.NewReader(sample), dialect, false)
+		}
+
+		counts := make(map[int]int)
+		lines := 0
+		quotingErr := false
+		for lines < delimiterSampleLines {
+			record, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				quotingErr = true
+				break
+			}
+			counts[len(record)]++
+			lines++
+		}
+
+		fields, mostLines := 0, 0
+		for count, n := range counts {
+			if n > mostLines || n == mostLines && count > fields {
+				fields, mostLines = count, n
+			}
+		}
+		if fields <= 1 {
+			continue
+		}
+
+		score := float64(mostLines) / float64(lines)
+		if quotingErr {
+			score /= 2
+		}
+		if score > bestScore || score == bestScore && fields > bestFields {
+			best, bestScore, bestFields = candidate, score, fields
+		}
+	}
+
+	return best, bestScore > 0
+}
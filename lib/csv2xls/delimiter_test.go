@@ -0,0 +1,65 @@
+package csv2xls
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestScoreDelimiters(t *testing.T) {
+	standard := csvDialect{quote: '"'}
+	tests := []struct {
+		name    string
+		sample  string
+		dialect csvDialect
+		want    rune
+		wantOk  bool
+	}{
+		{"semicolon", "a;b;c\n1;2;3\n4;5;6\n", standard, ';', true},
+		{"comma", "a,b,c\n1,2,3\n", standard, ',', true},
+		{"tab", "a\tb\n1\t2\n", standard, '\t', true},
+		{"pipe", "a|b|c|d\n1|2|3|4\n", standard, '|', true},
+		{"comma in decimals", "name;amount\nx;1,5\ny;2,25\nz;3\n", standard, ';', true},
+		{"consistent beats more fields", "a;b\n1,5;2,5,7\n3,1;4\n", standard, ';', true},
+		{"tie goes to more fields", "a,b,c;d\n", standard, ',', true},
+		{"tie goes to the first candidate", "a,b;c\n", standard, ';', true},
+		{"quoted delimiters", "\"a,b\";c\n\"d,e,f\";g\n", standard, ';', true},
+		{"one column", "a\nb\nc\n", standard, 0, false},
+		{"empty", "", standard, 0, false},
+		{"comment lines skipped", "# a,b,c\n# d,e,f\n# g,h,i\nx;y\n1;2\n", csvDialect{quote: '"', comment: '#'}, ';', true},
+		{"comment lines read without a comment character", "# a,b,c\n# d,e,f\n# g,h,i\nx;y\n1;2\n", standard, ',', true},
+		{"single quotes", "'1,5';'2,3'\n'3,4';'5,6'\n", csvDialect{quote: '\''}, ';', true},
+		{"quoted", "\"a;b;c\",d\n\"e;f;g\",h\n", standard, ',', true},
+		{"no quoting", "\"a;b;c\",d\n\"e;f;g\",h\n", csvDialect{}, ';', true},
+		{"candidate is the comment character", "|a;b\n1;2\n", csvDialect{quote: '"', comment: '|'}, ';', true},
+		{"escaped delimiters", "a\\,b;c\nd\\,e;f\n", csvDialect{quote: '"', escape: '\\'}, ';', true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := scoreDelimiters([]byte(tt.sample), tt.dialect)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("scoreDelimiters(%q) = %q, %v, want %q, %v", tt.sample, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestDetectDelimiterSampleLastLine(t *testing.T) {
+	// the line the sample ends in is cut and not looked at
+	document := strings.Repeat("a;b\n", delimiterSampleSize/4) + "c,d,e,f"
+	r, delimiter, ok, err := detectDelimiter(strings.NewReader(document), csvDialect{quote: '"'})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delimiter != ';' || !ok {
+		t.Errorf("detectDelimiter() = %q, %v, want ';', true", delimiter, ok)
+	}
+
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != document {
+		t.Errorf("detectDelimiter() reader does not read the document from the start")
+	}
+}
@@ -0,0 +1,271 @@
+package csv2xls
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// recordReader reads the records of a csv document, it is implemented by csv.Reader and dialectReader
+type recordReader interface {
+	Read() ([]string, error)
+	// InputOffset returns the offset of the end of the last record read
+	InputOffset() int64
+}
+
+// csvDialect is the syntax of a csv document
+type csvDialect struct {
+	delimiter string
+	// quote is the character around fields holding delimiters or line breaks, there is no quoting when it is 0
+	quote rune
+	// escape is the character before a character taken as it is, like \; or \", there is no escaping when it is 0
+	escape rune
+	// comment is the character starting the lines that are skipped, there are no comments when it is 0
+	comment          rune
+	trimLeadingSpace bool
+}
+
+// validate ...
+func (d csvDialect) validate() error {
+	switch {
+	case d.delimiter == "" || strings.ContainsAny(d.delimiter, "\r\n"):
+		return errors.New("csv delimiter must not be empty or hold line breaks")
+	case d.quote != 0 && strings.ContainsRune(d.delimiter, d.quote):
+		return errors.New("csv delimiter must not hold the quote character")
+	case d.escape != 0 && (strings.ContainsRune(d.delimiter, d.escape) || d.escape == d.quote):
+		return errors.New("csv escape character must differ from the delimiter and the quote character")
+	case d.comment != 0 && (strings.ContainsRune(d.delimiter, d.comment) || d.comment == d.quote):
+		return errors.New("csv comment character must differ from the delimiter and the quote character")
+	}
+
+	return nil
+}
+
+// isStandard tells whether encoding/csv reads the dialect
+func (d csvDialect) isStandard() bool {
+	return utf8.RuneCountInString(d.delimiter) == 1 && d.quote == '"' && d.escape == 0
+}
+
+// dialectReader reads the csv documents encoding/csv does not support: delimiters of more than one
+// character, another quote character or no quoting at all, and escape characters.
+// The errors are csv.ParseError like the ones of csv.Reader.
+type dialectReader struct {
+	r       *bufio.Reader
+	dialect csvDialect
+	// strict enforces the quoting rules and the number of fields of the first record
+	strict          bool
+	fieldsPerRecord int
+	line            int
+	offset          int64
+	record          []string
+	field           []byte
+}
+
+// newDialectReader ...
+func newDialectReader(r io.Reader, dialect csvDialect, strict bool) (*dialectReader, error) {
+	if err := dialect.validate(); err != nil {
+		return nil, err
+	}
+
+	return &dialectReader{
+		r:       bufio.NewReader(r),
+		dialect: dialect,
+		strict:  strict,
+	}, nil
+}
+
+// InputOffset ...
+func (d *dialectReader) InputOffset() int64 {
+	return d.offset
+}
+
+// readLine returns the next line, ending in "\n" unless it is the last one
+func (d *dialectReader) readLine() (string, error) {
+	line, err := d.r.ReadString('\n')
+	d.offset += int64(len(line))
+	if line == "" {
+		return "", err
+	}
+	d.line++
+	if strings.HasSuffix(line, "\r\n") {
+		line = line[:len(line)-2] + "\n"
+	}
+	if err == io.EOF {
+		err = nil
+	}
+
+	return line, err
+}
+
+// Read returns the next record, the slice is reused by the next call
+func (d *dialectReader) Read() ([]string, error) {
+	var line string
+	for {
+		l, err := d.readLine()
+		if err != nil {
+			return nil, err
+		}
+		first, _ := utf8.DecodeRuneInString(l)
+		if l == "\n" || d.dialect.comment != 0 && first == d.dialect.comment {
+			continue
+		}
+		line = l
+		break
+	}
+
+	startLine := d.line
+	parseErr := func(column int, err error) error {
+		return &csv.ParseError{StartLine: startLine, Line: d.line, Column: column, Err: err}
+	}
+	quote, escape, delimiter := d.dialect.quote, d.dialect.escape, d.dialect.delimiter
+
+	record := d.record[:0]
+	pos := 0
+	for {
+		d.field = d.field[:0]
+		if d.dialect.trimLeadingSpace {
+			for pos < len(line) {
+				r, size := utf8.DecodeRuneInString(line[pos:])
+				if r == '\n' || !unicode.IsSpace(r) {
+					break
+				}
+				pos += size
+			}
+		}
+
+		if r, size := utf8.DecodeRuneInString(line[pos:]); quote != 0 && r == quote {
+			pos += size
+			for closed := false; !closed; {
+				if pos >= len(line) {
+					// the quoted field goes on in the next line
+					l, err := d.readLine()
+					if err == io.EOF && d.strict {
+						return nil, parseErr(pos+1, csv.ErrQuote)
+					}
+					if err == io.EOF {
+						line, pos = "", 0
+						break
+					}
+					if err != nil {
+						return nil, err
+					}
+					line, pos = l, 0
+					continue
+				}
+
+				r, size := utf8.DecodeRuneInString(line[pos:])
+				switch {
+				case escape != 0 && r == escape && pos+size < len(line):
+					_, next := utf8.DecodeRuneInString(line[pos+size:])
+					d.field = append(d.field, line[pos+size:pos+size+next]...)
+					pos += size + next
+				case r == quote:
+					pos += size
+					if next, _ := utf8.DecodeRuneInString(line[pos:]); next == quote {
+						// a doubled quote
+						d.field = append(d.field, line[pos:pos+size]...)
+						pos += size
+					} else {
+						closed = true
+					}
+				default:
+					d.field = append(d.field, line[pos:pos+size]...)
+					pos += size
+				}
+			}
+
+			// the closing quote is followed by the delimiter or the end of the line,
+			// otherwise it is taken as it is together with the rest of the field
+			if pos < len(line) && line[pos] != '\n' && !strings.HasPrefix(line[pos:], delimiter) {
+				if d.strict {
+					// the column of the closing quote, like csv.Reader
+					return nil, parseErr(pos-utf8.RuneLen(quote)+1, csv.ErrQuote)
+				}
+				d.field = utf8.AppendRune(d.field, quote)
+			}
+		}
+
+		for pos < len(line) && line[pos] != '\n' && !strings.HasPrefix(line[pos:], delimiter) {
+			r, size := utf8.DecodeRuneInString(line[pos:])
+			if escape != 0 && r == escape && pos+size < len(line) && line[pos+size] != '\n' {
+				_, next := utf8.DecodeRuneInString(line[pos+size:])
+				d.field = append(d.field, line[pos+size:pos+size+next]...)
+				pos += size + next
+				continue
+			}
+			if d.strict && quote != 0 && r == quote {
+				return nil, parseErr(pos+1, csv.ErrBareQuote)
+			}
+			d.field = append(d.field, line[pos:pos+size]...)
+			pos += size
+		}
+
+		record = append(record, string(d.field))
+		if !strings.HasPrefix(line[pos:], delimiter) {
+			break
+		}
+		pos += len(delimiter)
+	}
+	d.record = record
+
+	if d.strict && d.fieldsPerRecord == 0 {
+		d.fieldsPerRecord = len(record)
+	} else if d.strict && len(record) != d.fieldsPerRecord {
+		return record, &csv.ParseError{StartLine: startLine, Line: startLine, Column: 1, Err: csv.ErrFieldCount}
+	}
+
+	return record, nil
+}
+
+// lineSkipper drops the first head lines and the last tail lines of a text, like the title above
+// and the totals below the table of an export
+type lineSkipper struct {
+	r          *bufio.Reader
+	head, tail int
+	// pending are the last lines read, they are passed on when tail more lines follow them
+	pending [][]byte
+	out     []byte
+	err     error
+}
+
+// newLineSkipper ...
+func newLineSkipper(r io.Reader, head int, tail int) *lineSkipper {
+	return &lineSkipper{
+		r:    bufio.NewReader(r),
+		head: head,
+		tail: tail,
+	}
+}
+
+// Read ...
+func (s *lineSkipper) Read(p []byte) (int, error) {
+	for len(s.out) == 0 && s.err == nil {
+		line, err := s.r.ReadBytes('\n')
+		s.err = err
+		switch {
+		case len(line) == 0:
+		case s.head > 0:
+			s.head--
+		case s.tail == 0:
+			s.out = line
+		default:
+			s.pending = append(s.pending, line)
+			if len(s.pending) > s.tail {
+				s.out = s.pending[0]
+				s.pending = s.pending[1:]
+			}
+		}
+	}
+
+	n := copy(p, s.out)
+	s.out = s.out[n:]
+	if n == 0 {
+		return 0, s.err
+	}
+
+	return n, nil
+}
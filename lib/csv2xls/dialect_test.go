@@ -0,0 +1,120 @@
+package csv2xls
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDialectReader(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		dialect  csvDialect
+		want     [][]string
+	}{
+		{"delimiter of two characters", "a||b||c\n1||2||3\n", csvDialect{delimiter: "||", quote: '"'}, [][]string{{"a", "b", "c"}, {"1", "2", "3"}}},
+		{"single quotes", "'a;b';c\n", csvDialect{delimiter: ";", quote: '\''}, [][]string{{"a;b", "c"}}},
+		{"doubled quote", "'it''s';x\n", csvDialect{delimiter: ";", quote: '\''}, [][]string{{"it's", "x"}}},
+		{"no quoting", "\"a\";b\n", csvDialect{delimiter: ";"}, [][]string{{"\"a\"", "b"}}},
+		{"escaped delimiter", "a\\;b;c\n", csvDialect{delimiter: ";", quote: '"', escape: '\\'}, [][]string{{"a;b", "c"}}},
+		{"escaped quote", "\"a\\\"b\";c\n", csvDialect{delimiter: ";", quote: '"', escape: '\\'}, [][]string{{"a\"b", "c"}}},
+		{"escape at the end of the line", "a\\\nb\n", csvDialect{delimiter: ";", quote: '"', escape: '\\'}, [][]string{{"a\\"}, {"b"}}},
+		{"quoted line break", "'a\nb';c\nd;e\n", csvDialect{delimiter: ";", quote: '\''}, [][]string{{"a\nb", "c"}, {"d", "e"}}},
+		{"crlf", "a;b\r\nc;d\r\n", csvDialect{delimiter: ";", quote: '\''}, [][]string{{"a", "b"}, {"c", "d"}}},
+		{"quoted crlf", "'a\r\nb';c\r\n", csvDialect{delimiter: ";", quote: '\''}, [][]string{{"a\nb", "c"}}},
+		{"comment and empty lines", "#x;y\n\na;b\n#z\n", csvDialect{delimiter: ";", quote: '\'', comment: '#'}, [][]string{{"a", "b"}}},
+		{"trim leading space", "a; b;  c\n", csvDialect{delimiter: ";", quote: '\'', trimLeadingSpace: true}, [][]string{{"a", "b", "c"}}},
+		{"text after a closing quote", "'a'x;b\n", csvDialect{delimiter: ";", quote: '\''}, [][]string{{"a'x", "b"}}},
+		{"unterminated quote", "'abc\n", csvDialect{delimiter: ";", quote: '\''}, [][]string{{"abc\n"}}},
+		{"empty fields", ";;\n", csvDialect{delimiter: ";", quote: '\''}, [][]string{{"", "", ""}}},
+		{"last line without a line break", "a;b\nc;d", csvDialect{delimiter: ";", quote: '\''}, [][]string{{"a", "b"}, {"c", "d"}}},
+		{"multi-byte delimiter", "a→b→c\n", csvDialect{delimiter: "→", quote: '\''}, [][]string{{"a", "b", "c"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := newDialectReader(strings.NewReader(tt.document), tt.dialect, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got [][]string
+			for {
+				record, err := r.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, append([]string(nil), record...))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("records %q, want %q", got, tt.want)
+			}
+			if r.InputOffset() != int64(len(tt.document)) {
+				t.Errorf("InputOffset() = %d, want %d", r.InputOffset(), len(tt.document))
+			}
+		})
+	}
+}
+
+func TestCsvDialectValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect csvDialect
+		wantErr bool
+	}{
+		{"standard", csvDialect{delimiter: ";", quote: '"'}, false},
+		{"all characters", csvDialect{delimiter: "||", quote: '\'', escape: '\\', comment: '#'}, false},
+		{"empty delimiter", csvDialect{quote: '"'}, true},
+		{"line break in the delimiter", csvDialect{delimiter: ";\n", quote: '"'}, true},
+		{"quote in the delimiter", csvDialect{delimiter: "\"", quote: '"'}, true},
+		{"escape is the quote", csvDialect{delimiter: ";", quote: '"', escape: '"'}, true},
+		{"escape in the delimiter", csvDialect{delimiter: ";", quote: '"', escape: ';'}, true},
+		{"comment in the delimiter", csvDialect{delimiter: "#;", quote: '"', comment: '#'}, true},
+		{"comment is the quote", csvDialect{delimiter: ";", quote: '\'', comment: '\''}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.dialect.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLineSkipper(t *testing.T) {
+	tests := []struct {
+		name       string
+		document   string
+		head, tail int
+		want       string
+	}{
+		{"nothing skipped", "a\nb\n", 0, 0, "a\nb\n"},
+		{"title and totals", "title\na\nb\ntotal\n", 1, 1, "a\nb\n"},
+		{"totals without a line break", "title\na\nb\ntotal", 1, 1, "a\nb\n"},
+		{"last line without a line break", "title\na\nb", 1, 0, "a\nb"},
+		{"two title lines", "t1\nt2\na\n", 2, 0, "a\n"},
+		{"two total lines", "a\nb\nc\nd", 0, 2, "a\nb\n"},
+		{"crlf", "title\r\na\r\n", 1, 0, "a\r\n"},
+		{"empty lines count", "\n\na\n", 2, 0, "a\n"},
+		{"more head lines than lines", "a\nb\n", 5, 0, ""},
+		{"more tail lines than lines", "a\nb\n", 0, 5, ""},
+		{"empty document", "", 1, 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// a byte at a time, so the lines are passed on in several reads
+			r := iotest.OneByteReader(newLineSkipper(strings.NewReader(tt.document), tt.head, tt.tail))
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("lines %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package csv2xls
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding is the character encoding of a csv document, it is transcoded to UTF-8 before parsing
+type Encoding int
+
+const (
+	// EncodingUTF8 is the default, the csv document is read as it is
+	EncodingUTF8 Encoding = iota
+	// EncodingWindows1252 is the Western European code page of Windows
+	EncodingWindows1252
+	// EncodingISO88591 is Latin-1
+	EncodingISO88591
+	// EncodingUTF16LE is UTF-16 with the low byte first
+	EncodingUTF16LE
+	// EncodingUTF16BE is UTF-16 with the high byte first
+	EncodingUTF16BE
+	// EncodingAuto guesses the encoding from the start of the csv document when it has no byte order mark:
+	// UTF-16 when it has zero bytes, UTF-8 when it is valid UTF-8 and Windows-1252 otherwise
+	EncodingAuto
+)
+
+// encodingSampleSize is the number of bytes EncodingAuto looks at
+const encodingSampleSize = 64 * 1024
+
+// encodingNames are the names of the encodings, the first one is used by String
+var encodingNames = map[Encoding][]string{
+	EncodingUTF8:        {"utf-8", "utf8"},
+	EncodingWindows1252: {"windows-1252", "cp1252", "win1252"},
+	EncodingISO88591:    {"iso-8859-1", "latin1", "latin-1"},
+	EncodingUTF16LE:     {"utf-16le", "utf16le"},
+	EncodingUTF16BE:     {"utf-16be", "utf16be"},
+	EncodingAuto:        {"auto"},
+}
+
+// ParseEncoding parses the name of an encoding: "utf-8", "windows-1252", "iso-8859-1", "utf-16le", "utf-16be" or "auto"
+func ParseEncoding(name string) (Encoding, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return EncodingUTF8, nil
+	}
+	for encoding, names := range encodingNames {
+		for _, encodingName := range names {
+			if name == encodingName {
+				return encoding, nil
+			}
+		}
+	}
+
+	return EncodingUTF8, fmt.Errorf(`unknown encoding "%s", expected utf-8, windows-1252, iso-8859-1, utf-16le, utf-16be or auto`, name)
+}
+
+// String returns the name of the encoding
+func (e Encoding) String() string {
+	if names, ok := encodingNames[e]; ok {
+		return names[0]
+	}
+
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// windows1252 are the characters of the bytes 0x80 to 0x9F, the other bytes are the same as in Latin-1
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// decodeReader returns the UTF-8 text of r. A byte order mark takes precedence over the encoding and is removed.
+// The encoding used is returned, it is the guessed one for EncodingAuto.
+func decodeReader(r io.Reader, encoding Encoding) (io.Reader, Encoding, error) {
+	src := bufio.NewReaderSize(r, encodingSampleSize)
+
+	bom, err := src.Peek(3)
+	if err != nil && err != io.EOF {
+		return nil, encoding, err
+	}
+	switch {
+	case bytes.HasPrefix(bom, []byte{0xEF, 0xBB, 0xBF}):
+		_, _ = src.Discard(3)
+		return src, EncodingUTF8, nil
+	case bytes.HasPrefix(bom, []byte{0xFF, 0xFE}):
+		_, _ = src.Discard(2)
+		return newDecodingReader(src, EncodingUTF16LE), EncodingUTF16LE, nil
+	case bytes.HasPrefix(bom, []byte{0xFE, 0xFF}):
+		_, _ = src.Discard(2)
+		return newDecodingReader(src, EncodingUTF16BE), EncodingUTF16BE, nil
+	}
+
+	if encoding == EncodingAuto {
+		sample, err := src.Peek(encodingSampleSize)
+		if err != nil && err != io.EOF {
+			return nil, encoding, err
+		}
+		encoding = guessEncoding(sample, err == nil)
+	}
+	if encoding == EncodingUTF8 {
+		return src, encoding, nil
+	}
+
+	return newDecodingReader(src, encoding), encoding, nil
+}
+
+// guessEncoding guesses the encoding of the start of a document, truncated tells whether the document goes on
+func guessEncoding(sample []byte, truncated bool) Encoding {
+	var evenZeros, oddZeros int
+	for i, b := range sample {
+		if b == 0 && i%2 == 0 {
+			evenZeros++
+		} else if b == 0 {
+			oddZeros++
+		}
+	}
+	// the zero bytes are the high bytes of the ASCII characters
+	if oddZeros > len(sample)/8 && oddZeros > evenZeros {
+		return EncodingUTF16LE
+	}
+	if evenZeros > len(sample)/8 {
+		return EncodingUTF16BE
+	}
+
+	if truncated {
+		// the sample may end in the middle of a character
+		for i := len(sample) - 1; i >= 0 && i >= len(sample)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(sample[i]) {
+				if !utf8.FullRune(sample[i:]) {
+					sample = sample[:i]
+				}
+				break
+			}
+		}
+	}
+	if utf8.Valid(sample) {
+		return EncodingUTF8
+	}
+
+	return EncodingWindows1252
+}
+
+// decodingReader transcodes a single-byte or UTF-16 encoded text to UTF-8
+type decodingReader struct {
+	src    *bufio.Reader
+	decode func(src *bufio.Reader) (rune, error)
+	buf    []byte
+	err    error
+}
+
+// newDecodingReader ...
+func newDecodingReader(src *bufio.Reader, encoding Encoding) *decodingReader {
+	d := &decodingReader{src: src, buf: make([]byte, 0, 4096)}
+	switch encoding {
+	case EncodingWindows1252:
+		d.decode = decodeWindows1252
+	case EncodingUTF16LE:
+		d.decode = func(src *bufio.Reader) (rune, error) { return decodeUTF16(src, false) }
+	case EncodingUTF16BE:
+		d.decode = func(src *bufio.Reader) (rune, error) { return decodeUTF16(src, true) }
+	default:
+		d.decode = decodeISO88591
+	}
+
+	return d
+}
+
+// Read ...
+func (d *decodingReader) Read(p []byte) (int, error) {
+	for len(d.buf) < len(p) && d.err == nil {
+		r, err := d.decode(d.src)
+		if err != nil {
+			d.err = err
+			break
+		}
+		d.buf = utf8.AppendRune(d.buf, r)
+	}
+
+	n := copy(p, d.buf)
+	d.buf = d.buf[:copy(d.buf, d.buf[n:])]
+	if n == 0 {
+		return 0, d.err
+	}
+
+	return n, nil
+}
+
+// decodeISO88591 ...
+func decodeISO88591(src *bufio.Reader) (rune, error) {
+	b, err := src.ReadByte()
+	return rune(b), err
+}
+
+// decodeWindows1252 ...
+func decodeWindows1252(src *bufio.Reader) (rune, error) {
+	b, err := src.ReadByte()
+	if b >= 0x80 && b < 0xA0 {
+		return windows1252[b-0x80], err
+	}
+
+	return rune(b), err
+}
+
+// decodeUTF16 reads one character, a surrogate pair takes two code units
+func decodeUTF16(src *bufio.Reader, bigEndian bool) (rune, error) {
+	readUnit := func() (rune, error) {
+		var unit [2]byte
+		if _, err := io.ReadFull(src, unit[:]); err != nil {
+			return 0, err
+		}
+		if bigEndian {
+			return rune(unit[0])<<8 | rune(unit[1]), nil
+		}
+		return rune(unit[1])<<8 | rune(unit[0]), nil
+	}
+
+	r, err := readUnit()
+	if err == io.ErrUnexpectedEOF {
+		// a dangling byte at the end
+		return utf8.RuneError, nil
+	}
+	if err != nil || !utf16.IsSurrogate(r) {
+		return r, err
+	}
+
+	b, err := src.Peek(2)
+	if err != nil {
+		return utf8.RuneError, nil
+	}
+	var low rune
+	if bigEndian {
+		low = rune(b[0])<<8 | rune(b[1])
+	} else {
+		low = rune(b[1])<<8 | rune(b[0])
+	}
+	decoded := utf16.DecodeRune(r, low)
+	if decoded != utf8.RuneError {
+		_, _ = src.Discard(2)
+	}
+
+	return decoded, nil
+}
@@ -0,0 +1,72 @@
+package csv2xls
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestDecodeReader(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		encoding     Encoding
+		want         string
+		wantEncoding Encoding
+	}{
+		{"utf-8", "a;café\n", EncodingUTF8, "a;café\n", EncodingUTF8},
+		{"utf-8 byte order mark", "\xEF\xBB\xBFa;b", EncodingWindows1252, "a;b", EncodingUTF8},
+		{"utf-16le byte order mark", "\xFF\xFEa\x00;\x00b\x00", EncodingUTF8, "a;b", EncodingUTF16LE},
+		{"utf-16be byte order mark", "\xFE\xFF\x00a\x00;\x00b", EncodingUTF8, "a;b", EncodingUTF16BE},
+		{"windows-1252", "\x80 caf\xE9", EncodingWindows1252, "€ café", EncodingWindows1252},
+		{"iso-8859-1", "\x80 caf\xE9", EncodingISO88591, "\u0080 café", EncodingISO88591},
+		{"utf-16le surrogate pair", "=\x00\x3D\xD8\x00\xDE", EncodingUTF16LE, "=😀", EncodingUTF16LE},
+		{"utf-16be unpaired surrogate", "\xD8\x3D\x00a", EncodingUTF16BE, "�a", EncodingUTF16BE},
+		{"utf-16le dangling byte", "a\x00b", EncodingUTF16LE, "a�", EncodingUTF16LE},
+		{"auto utf-16le", "a\x00;\x00b\x00", EncodingAuto, "a;b", EncodingUTF16LE},
+		{"auto utf-16be", "\x00a\x00;\x00b", EncodingAuto, "a;b", EncodingUTF16BE},
+		{"auto utf-8", "a;café", EncodingAuto, "a;café", EncodingUTF8},
+		{"auto windows-1252", "a;caf\xE9", EncodingAuto, "a;café", EncodingWindows1252},
+		{"auto empty", "", EncodingAuto, "", EncodingUTF8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, encoding, err := decodeReader(bytes.NewReader([]byte(tt.input)), tt.encoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want || encoding != tt.wantEncoding {
+				t.Errorf("decodeReader() = %q, %s, want %q, %s", got, encoding, tt.want, tt.wantEncoding)
+			}
+		})
+	}
+}
+
+func TestGuessEncoding(t *testing.T) {
+	tests := []struct {
+		name      string
+		sample    string
+		truncated bool
+		want      Encoding
+	}{
+		{"ascii", "a;b\n1;2\n", false, EncodingUTF8},
+		{"utf-8", "a;café\n", false, EncodingUTF8},
+		{"utf-8 cut in a character", "a;caf\xC3", true, EncodingUTF8},
+		{"incomplete character at the end", "a;caf\xC3", false, EncodingWindows1252},
+		{"windows-1252", "a;caf\xE9\n", false, EncodingWindows1252},
+		{"utf-16le", "a\x00;\x00b\x00\n\x00", false, EncodingUTF16LE},
+		{"utf-16be", "\x00a\x00;\x00b\x00\n", false, EncodingUTF16BE},
+		{"few zero bytes", "a;b;c;d;e;f;g;h;i;j\x00", false, EncodingUTF8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := guessEncoding([]byte(tt.sample), tt.truncated); got != tt.want {
+				t.Errorf("guessEncoding(%q, %v) = %s, want %s", tt.sample, tt.truncated, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package csv2xls
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/omniboost/csv2xls/lib/goxls"
+)
+
+// maxReportedMismatches is the number of rows not conforming to the schema that are reported one by one
+const maxReportedMismatches = 100
+
+// Schema describes the columns of a csv document, the columns are matched by name with the first row.
+// It is read from JSON like
+//
+//	{"columns": [
+//		{"name": "order_no", "type": "text", "width": 12},
+//		{"name": "amount", "type": "currency", "format": "#,##0.00 €"},
+//		{"name": "arrival", "type": "date", "layout": "02.01.2006", "format": "dd.mm.yyyy"},
+//		{"name": "internal_id", "hidden": true}
+//	]}
+type Schema struct {
+	Columns []SchemaColumn `json:"columns"`
+}
+
+// SchemaColumn describes one column of a csv document
+type SchemaColumn struct {
+	// Name is the value of the column in the first row
+	Name string `json:"name"`
+	// Type is the name of a goxls.CellType, the values that look like numbers or dates are stored as numbers when it is empty
+	Type string `json:"type,omitempty"`
+	// Layout is the time layout of the values of a date column, see time.Parse, the date layouts of the converter by default
+	Layout string `json:"layout,omitempty"`
+	// Format is the Excel format code of the numeric and date cells
+	Format string `json:"format,omitempty"`
+	// Width is the width of the column in characters, the column width is fitted when it is 0
+	Width  int  `json:"width,omitempty"`
+	Hidden bool `json:"hidden,omitempty"`
+
+	cellType goxls.CellType
+}
+
+// LoadSchema reads the schema from the JSON file fileName
+func LoadSchema(fileName string) (*Schema, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	schema, err := ParseSchema(f)
+	if err != nil {
+		return nil, fmt.Errorf(`schema file "%s": %w`, fileName, err)
+	}
+
+	return schema, nil
+}
+
+// ParseSchema reads the schema from JSON and validates it
+func ParseSchema(r io.Reader) (*Schema, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var schema Schema
+	if err := decoder.Decode(&schema); err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool)
+	for i := range schema.Columns {
+		column := &schema.Columns[i]
+		column.Name = strings.TrimSpace(column.Name)
+		switch {
+		case column.Name == "":
+			return nil, fmt.Errorf("column %d has no name", i+1)
+		case names[column.Name]:
+			return nil, fmt.Errorf(`column "%s" is described twice`, column.Name)
+		case column.Width < 0 || column.Width > 255:
+			return nil, fmt.Errorf(`column "%s": width must be between 0 and 255`, column.Name)
+		}
+		names[column.Name] = true
+
+		cellType, err := goxls.ParseCellType(column.Type)
+		if err != nil {
+			return nil, fmt.Errorf(`column "%s": %w`, column.Name, err)
+		}
+		if column.Layout != "" && cellType != goxls.CellTypeDate {
+			return nil, fmt.Errorf(`column "%s": only date columns have a layout`, column.Name)
+		}
+		column.cellType = cellType
+	}
+
+	return &schema, nil
+}
+
+// schemaColumns are the settings of the columns described by a schema, by zero-based column index
+type schemaColumns struct {
+	types  map[int]goxls.ColumnType
+	styles map[int]goxls.Style
+	widths map[int]int
+	hidden map[int]bool
+}
+
+// resolve matches the columns of the schema with the header row. The number formats and widths set by the
+// With methods of the converter take precedence over the ones of the schema.
+func (s *Schema) resolve(header []string, columnStyles map[int]goxls.Style) (schemaColumns, error) {
+	columnIndexes := make(map[string]int, len(header))
+	for columnIdx, name := range header {
+		name = strings.TrimSpace(name)
+		if _, ok := columnIndexes[name]; !ok {
+			columnIndexes[name] = columnIdx
+		}
+	}
+
+	resolved := schemaColumns{
+		types:  make(map[int]goxls.ColumnType),
+		styles: make(map[int]goxls.Style),
+		widths: make(map[int]int),
+		hidden: make(map[int]bool),
+	}
+	for columnIdx, style := range columnStyles {
+		resolved.styles[columnIdx] = style
+	}
+
+	var missing []string
+	for _, column := range s.Columns {
+		columnIdx, ok := columnIndexes[column.Name]
+		if !ok {
+			missing = append(missing, fmt.Sprintf(`"%s"`, column.Name))
+			continue
+		}
+
+		columnType := goxls.ColumnType{Type: column.cellType}
+		if column.Layout != "" {
+			columnType.DateLayouts = []string{column.Layout}
+		}
+		resolved.types[columnIdx] = columnType
+
+		if style := resolved.styles[columnIdx]; column.Format != "" && style.NumberFormat == "" {
+			style.NumberFormat = column.Format
+			resolved.styles[columnIdx] = style
+		}
+		if column.Width > 0 {
+			resolved.widths[columnIdx] = column.Width
+		}
+		if column.Hidden {
+			resolved.hidden[columnIdx] = true
+		}
+	}
+	if len(missing) > 0 {
+		return resolved, fmt.Errorf("schema columns %s are not in the header", strings.Join(missing, ", "))
+	}
+
+	return resolved, nil
+}
+
+// mismatchReporter reports the rows with cells that do not match the type of their column in the schema
+type mismatchReporter struct {
+	converter *Csv2XlsConverter
+	rows      int
+}
+
+// report logs the cells of a row that do not match their column type, err holds goxls.CellTypeError
+func (m *mismatchReporter) report(wsName string, err error) {
+	m.rows++
+	if m.rows > maxReportedMismatches {
+		return
+	}
+
+	var cells []string
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, cellErr := range joined.Unwrap() {
+			cells = append(cells, cellErr.Error())
+		}
+	} else {
+		cells = append(cells, err.Error())
+	}
+	m.converter.logf("Worksheet %q does not conform to the schema, %s", wsName, strings.Join(cells, ", "))
+	if m.rows == maxReportedMismatches {
+		m.converter.logf("The next rows not conforming to the schema are counted only")
+	}
+}
+
+// summary logs the number of rows not conforming to the schema
+func (m *mismatchReporter) summary() {
+	if m.rows > 0 {
+		m.converter.logf("%d rows do not conform to the schema, the cells not matching their column type are stored as text", m.rows)
+	}
+}
@@ -0,0 +1,106 @@
+package csv2xls
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/omniboost/csv2xls/lib/goxls"
+)
+
+func TestParseSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		want    []SchemaColumn
+		wantErr string
+	}{
+		{
+			"columns",
+			`{"columns": [
+				{"name": " order_no ", "type": "text", "width": 12},
+				{"name": "arrival", "type": "Date", "layout": "02.01.2006", "format": "dd.mm.yyyy"},
+				{"name": "internal_id", "hidden": true}
+			]}`,
+			[]SchemaColumn{
+				{Name: "order_no", Type: "text", Width: 12, cellType: goxls.CellTypeText},
+				{Name: "arrival", Type: "Date", Layout: "02.01.2006", Format: "dd.mm.yyyy", cellType: goxls.CellTypeDate},
+				{Name: "internal_id", Hidden: true, cellType: goxls.CellTypeAuto},
+			},
+			"",
+		},
+		{"no columns", `{}`, nil, ""},
+		{"invalid json", `{"columns": [`, nil, "unexpected EOF"},
+		{"unknown field", `{"columns": [{"name": "a", "hide": true}]}`, nil, `unknown field "hide"`},
+		{"no name", `{"columns": [{"type": "int"}]}`, nil, "column 1 has no name"},
+		{"described twice", `{"columns": [{"name": "a"}, {"name": "a "}]}`, nil, `column "a" is described twice`},
+		{"width too large", `{"columns": [{"name": "a", "width": 256}]}`, nil, `column "a": width must be between 0 and 255`},
+		{"negative width", `{"columns": [{"name": "a", "width": -1}]}`, nil, `column "a": width must be between 0 and 255`},
+		{"unknown type", `{"columns": [{"name": "a", "type": "number"}]}`, nil, `column "a": unknown cell type "number"`},
+		{"layout of a text column", `{"columns": [{"name": "a", "type": "text", "layout": "2006"}]}`, nil, `column "a": only date columns have a layout`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := ParseSchema(strings.NewReader(tt.json))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseSchema() error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(schema.Columns, tt.want) {
+				t.Errorf("ParseSchema() = %+v, want %+v", schema.Columns, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaResolve(t *testing.T) {
+	schema, err := ParseSchema(strings.NewReader(`{"columns": [
+		{"name": "amount", "type": "currency", "format": "#,##0.00 €", "width": 14},
+		{"name": "rate", "type": "percent", "format": "0%"},
+		{"name": "arrival", "type": "date", "layout": "02.01.2006"},
+		{"name": "id", "hidden": true}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the number format set with WithColumnFormat takes precedence
+	columnStyles := map[int]goxls.Style{1: {NumberFormat: "0.0%"}}
+	columns, err := schema.resolve([]string{"id", " rate ", "arrival", "amount", "id"}, columnStyles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantTypes := map[int]goxls.ColumnType{
+		0: {Type: goxls.CellTypeAuto},
+		1: {Type: goxls.CellTypePercent},
+		2: {Type: goxls.CellTypeDate, DateLayouts: []string{"02.01.2006"}},
+		3: {Type: goxls.CellTypeCurrency},
+	}
+	if !reflect.DeepEqual(columns.types, wantTypes) {
+		t.Errorf("types %+v, want %+v", columns.types, wantTypes)
+	}
+	wantStyles := map[int]goxls.Style{1: {NumberFormat: "0.0%"}, 3: {NumberFormat: "#,##0.00 €"}}
+	if !reflect.DeepEqual(columns.styles, wantStyles) {
+		t.Errorf("styles %+v, want %+v", columns.styles, wantStyles)
+	}
+	if wantWidths := map[int]int{3: 14}; !reflect.DeepEqual(columns.widths, wantWidths) {
+		t.Errorf("widths %v, want %v", columns.widths, wantWidths)
+	}
+	if wantHidden := map[int]bool{0: true}; !reflect.DeepEqual(columns.hidden, wantHidden) {
+		t.Errorf("hidden %v, want %v", columns.hidden, wantHidden)
+	}
+	if len(columnStyles) != 1 {
+		t.Errorf("the column styles of the converter are changed: %v", columnStyles)
+	}
+
+	_, err = schema.resolve([]string{"id", "amount"}, nil)
+	if want := `schema columns "rate", "arrival" are not in the header`; err == nil || err.Error() != want {
+		t.Errorf("resolve() error %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,52 @@
+package csv2xls
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/omniboost/csv2xls/lib/goxls"
+)
+
+// DefaultSheetName is the name of the first worksheet
+const DefaultSheetName = "worksheet"
+
+// blankSheetName is the name of the worksheet of the rows with an empty value in the split column
+const blankSheetName = "(blank)"
+
+// DefaultContinuationSheetName is the name of the worksheets the rows beyond the limit of a worksheet continue on.
+// The placeholder {name} is replaced by the name of the first worksheet, {i} by the number of the continuation
+// starting at 1 and {n} by the number of the worksheet starting at 1 for the first worksheet,
+// so "{name} ({n})" names the worksheets "worksheet", "worksheet (2)", "worksheet (3)"…
+const DefaultContinuationSheetName = "{name}{i}"
+
+// formatContinuationSheetName returns the name of the continuation worksheet of the rows starting at rowsPerWorksheet*part
+func formatContinuationSheetName(format string, name string, part int) string {
+	return strings.NewReplacer(
+		"{name}", name,
+		"{i}", strconv.Itoa(part),
+		"{n}", strconv.Itoa(part+1),
+	).Replace(format)
+}
+
+// sheetNames hands out valid worksheet names that are unique case-insensitively
+type sheetNames struct {
+	used map[string]bool
+}
+
+// newSheetNames ...
+func newSheetNames() *sheetNames {
+	return &sheetNames{used: make(map[string]bool)}
+}
+
+// unique sanitises name and numbers it like "name (2)" when the name is taken already
+func (n *sheetNames) unique(name string) string {
+	name = goxls.SanitizeSheetName(name, DefaultSheetName)
+	unique := name
+	for i := 2; n.used[strings.ToLower(unique)]; i++ {
+		suffix := " (" + strconv.Itoa(i) + ")"
+		unique = goxls.TruncateSheetName(name, goxls.MaxSheetNameLength-len(suffix)) + suffix
+	}
+	n.used[strings.ToLower(unique)] = true
+
+	return unique
+}
@@ -0,0 +1,51 @@
+package csv2xls
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatContinuationSheetName(t *testing.T) {
+	tests := []struct {
+		format string
+		name   string
+		part   int
+		want   string
+	}{
+		{DefaultContinuationSheetName, "worksheet", 1, "worksheet1"},
+		{DefaultContinuationSheetName, "worksheet", 12, "worksheet12"},
+		{"{name} ({n})", "Data", 1, "Data (2)"},
+		{"{name} part {i} of {n}", "Data", 2, "Data part 2 of 3"},
+		{"{name}{name}", "ab", 1, "abab"},
+		{"More", "Data", 1, "More"},
+	}
+	for _, tt := range tests {
+		if got := formatContinuationSheetName(tt.format, tt.name, tt.part); got != tt.want {
+			t.Errorf("formatContinuationSheetName(%q, %q, %d) = %q, want %q", tt.format, tt.name, tt.part, got, tt.want)
+		}
+	}
+}
+
+func TestSheetNamesUnique(t *testing.T) {
+	long := strings.Repeat("ä", 31)
+	tests := []struct {
+		names []string
+		want  []string
+	}{
+		{[]string{"Data", "Other"}, []string{"Data", "Other"}},
+		{[]string{"Data", "data", "DATA"}, []string{"Data", "data (2)", "DATA (3)"}},
+		{[]string{"Data (2)", "Data", "Data"}, []string{"Data (2)", "Data", "Data (3)"}},
+		{[]string{"a/b", "a:b"}, []string{"a_b", "a_b (2)"}},
+		{[]string{"", "History", "'"}, []string{"worksheet", "worksheet (2)", "worksheet (3)"}},
+		{[]string{long, long}, []string{long, strings.Repeat("ä", 27) + " (2)"}},
+		{[]string{strings.Repeat("日", 40), strings.Repeat("日", 31)}, []string{strings.Repeat("日", 31), strings.Repeat("日", 27) + " (2)"}},
+	}
+	for _, tt := range tests {
+		names := newSheetNames()
+		for i, name := range tt.names {
+			if got := names.unique(name); got != tt.want[i] {
+				t.Errorf("unique(%q) after %q = %q, want %q", name, tt.names[:i], got, tt.want[i])
+			}
+		}
+	}
+}
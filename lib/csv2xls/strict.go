@@ -0,0 +1,138 @@
+package csv2xls
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxCsvErrorText is the number of characters of the malformed text a CsvError keeps
+const maxCsvErrorText = 200
+
+// CsvError is a malformed row of a csv document found in strict mode, see WithStrict
+type CsvError struct {
+	// File is the name of the csv file
+	File string
+	// Line is the 1-based line and Column the 1-based byte index in the line where the error occurred
+	Line   int
+	Column int
+	// Text is the malformed row as it is in the csv document
+	Text string
+	// Err is csv.ErrFieldCount, csv.ErrQuote or csv.ErrBareQuote
+	Err error
+}
+
+// Error ...
+func (e *CsvError) Error() string {
+	return fmt.Sprintf(`csv file "%s" line %d column %d: %v: %q`, e.File, e.Line, e.Column, e.Err, e.Text)
+}
+
+// Unwrap ...
+func (e *CsvError) Unwrap() error {
+	return e.Err
+}
+
+// CsvErrors are all the malformed rows of a csv document, see WithCollectErrors
+type CsvErrors []*CsvError
+
+// Error lists the errors one per line
+func (e CsvErrors) Error() string {
+	lines := make([]string, 0, len(e)+1)
+	lines = append(lines, fmt.Sprintf("%d malformed rows:", len(e)))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap ...
+func (e CsvErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// strictReader reads the rows of a csv document that enforces the quoting rules and the number of fields
+// of the first row, the malformed rows are returned as CsvError
+type strictReader struct {
+	csv  recordReader
+	raw  *rawRecorder
+	file string
+	// skippedLines are the lines dropped before csv reads the document, see WithSkipLines,
+	// they are added to the lines of the errors so they count from the start of the file
+	skippedLines int
+	collect      bool
+	errs         CsvErrors
+}
+
+// Read returns the next row. When the errors are collected, the malformed rows are skipped and
+// CsvErrors is returned at the end of the csv document instead of io.EOF.
+func (r *strictReader) Read() ([]string, error) {
+	for {
+		offset := r.csv.InputOffset()
+		r.raw.discard(offset)
+
+		row, err := r.csv.Read()
+		if err == io.EOF && len(r.errs) > 0 {
+			return nil, r.errs
+		}
+		var parseErr *csv.ParseError
+		if !errors.As(err, &parseErr) {
+			return row, err
+		}
+
+		csvErr := &CsvError{
+			File:   r.file,
+			Line:   parseErr.Line + r.skippedLines,
+			Column: parseErr.Column,
+			Text:   r.raw.text(offset, r.csv.InputOffset()),
+			Err:    parseErr.Err,
+		}
+		if !r.collect {
+			return nil, csvErr
+		}
+		r.errs = append(r.errs, csvErr)
+	}
+}
+
+// rawRecorder keeps the bytes read from r from the start of the row being parsed, so the text of a malformed
+// row can be reported. The bytes before the row are discarded, so it holds the row and the read-ahead only.
+type rawRecorder struct {
+	r io.Reader
+	// start is the offset of the first byte of buf
+	start int64
+	buf   []byte
+}
+
+// Read ...
+func (rr *rawRecorder) Read(p []byte) (int, error) {
+	n, err := rr.r.Read(p)
+	rr.buf = append(rr.buf, p[:n]...)
+
+	return n, err
+}
+
+// discard forgets the bytes before offset
+func (rr *rawRecorder) discard(offset int64) {
+	n := int(offset - rr.start)
+	rr.buf = rr.buf[:copy(rr.buf, rr.buf[n:])]
+	rr.start = offset
+}
+
+// text returns the bytes from offset from to offset to without the line break at the end,
+// cut to maxCsvErrorText characters
+func (rr *rawRecorder) text(from int64, to int64) string {
+	text := strings.TrimRight(string(rr.buf[from-rr.start:to-rr.start]), "\r\n")
+	if utf8.RuneCountInString(text) > maxCsvErrorText {
+		text = string([]rune(text)[:maxCsvErrorText]) + "…"
+	}
+
+	return text
+}
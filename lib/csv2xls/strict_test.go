@@ -0,0 +1,92 @@
+package csv2xls
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// readStrict reads all the rows of the csv document in strict mode and returns the error ending them
+func readStrict(c *Csv2XlsConverter, document string) error {
+	nextRow, err := c.WithStrict(true).newRowReader(strings.NewReader(document))
+	if err != nil {
+		return err
+	}
+	for {
+		if _, err := nextRow(); err != nil {
+			return err
+		}
+	}
+}
+
+func TestStrictCsvError(t *testing.T) {
+	tests := []struct {
+		name       string
+		document   string
+		quote      rune
+		delimiter  string
+		skipLines  int
+		wantLine   int
+		wantColumn int
+		wantText   string
+		wantErr    error
+	}{
+		{"field count", "a;b\n1;2\n3\n", '"', ";", 0, 3, 1, "3", csv.ErrFieldCount},
+		{"bare quote", "a;b\n1;x\"y\n", '"', ";", 0, 2, 4, "1;x\"y", csv.ErrBareQuote},
+		{"character after a closing quote", "a;b\n\"1\"x;2\n", '"', ";", 0, 2, 3, "\"1\"x;2", csv.ErrQuote},
+		{"after a quoted line break", "a;b\n\"x\ny\";2\n3\n", '"', ";", 0, 4, 1, "3", csv.ErrFieldCount},
+		{"skipped lines", "title\n\na;b\n1;2\n3\n", '"', ";", 2, 5, 1, "3", csv.ErrFieldCount},
+		{"skipped lines and a quote error", "title\na;b\n\"1\"x;2\n", '"', ";", 1, 3, 3, "\"1\"x;2", csv.ErrQuote},
+		{"dialect field count", "a||b\n1||2\n3\n", '"', "||", 0, 3, 1, "3", csv.ErrFieldCount},
+		{"dialect character after a closing quote", "a;b\n'1'x;2\n", '\'', ";", 0, 2, 3, "'1'x;2", csv.ErrQuote},
+		{"dialect skipped lines", "title\na;b\n1\n", '\'', ";", 1, 3, 1, "1", csv.ErrFieldCount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCsv2XlsConverter("test.csv", "test.xls", tt.delimiter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = readStrict(c.WithQuote(tt.quote).WithSkipLines(tt.skipLines, 0), tt.document)
+
+			var csvErr *CsvError
+			if !errors.As(err, &csvErr) {
+				t.Fatalf("error %v is not a CsvError", err)
+			}
+			if csvErr.File != "test.csv" || csvErr.Line != tt.wantLine || csvErr.Column != tt.wantColumn || csvErr.Text != tt.wantText {
+				t.Errorf("error at %s line %d column %d in %q, want test.csv line %d column %d in %q",
+					csvErr.File, csvErr.Line, csvErr.Column, csvErr.Text, tt.wantLine, tt.wantColumn, tt.wantText)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStrictCollectErrors(t *testing.T) {
+	c, err := NewCsv2XlsConverter("test.csv", "test.xls", ";")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = readStrict(c.WithCollectErrors(true).WithSkipLines(1, 0), "title\na;b\n1\n1;2\n\"1\"x;2\n1;2;3\n")
+
+	var csvErrs CsvErrors
+	if !errors.As(err, &csvErrs) {
+		t.Fatalf("error %v is not CsvErrors", err)
+	}
+	wantLines := []int{3, 5, 6}
+	if len(csvErrs) != len(wantLines) {
+		t.Fatalf("%d errors, want %d: %v", len(csvErrs), len(wantLines), err)
+	}
+	for i, csvErr := range csvErrs {
+		if csvErr.Line != wantLines[i] {
+			t.Errorf("error %d at line %d, want %d", i, csvErr.Line, wantLines[i])
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		t.Errorf("CsvErrors wraps io.EOF")
+	}
+}
@@ -0,0 +1,373 @@
+package csv2xls
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/omniboost/csv2xls/lib/goxls"
+)
+
+// workbookWriter writes the worksheets of one or more csv documents into one workbook.
+// The worksheets share the fonts, the styles and the table of distinct text values of the workbook.
+type workbookWriter struct {
+	// converter holds the settings of the workbook, like the document properties and the default font
+	converter  *Csv2XlsConverter
+	workbook   goxls.Workbook
+	names      *sheetNames
+	worksheets []*goxls.WorksheetStream
+}
+
+// newWorkbookWriter ...
+func newWorkbookWriter(c *Csv2XlsConverter, stringCollection *goxls.StringCollection) *workbookWriter {
+	return &workbookWriter{
+		converter: c,
+		workbook: goxls.Workbook{
+			StringCollection: stringCollection,
+			Date1904:         c.date1904,
+			DefaultFont:      c.font,
+		},
+		names:      newSheetNames(),
+		worksheets: make([]*goxls.WorksheetStream, 0),
+	}
+}
+
+// writeCsvSheet writes the worksheets of the csv file of sheet
+func (ww *workbookWriter) writeCsvSheet(sheet *Csv2XlsConverter) error {
+	r, err := sheet.openCsv()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	nextRow, err := sheet.newRowReader(r)
+	if err != nil {
+		return err
+	}
+
+	err = ww.writeSheet(sheet, nextRow)
+	var csvErr *CsvError
+	var csvErrs CsvErrors
+	if errors.As(err, &csvErr) || errors.As(err, &csvErrs) {
+		// the errors of the malformed rows name the csv file already
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf(`csv file "%s": %w`, sheet.csvFileName, err)
+	}
+
+	return nil
+}
+
+// worksheetGroup are the worksheets of the rows of a csv document, or of the rows with one value of the split column
+type worksheetGroup struct {
+	name       string
+	worksheets []*goxls.WorksheetStream
+	// columns are the csv columns of the worksheets holding the columns beyond goxls.MaxColumns
+	columns [][]int
+	// part are the worksheets of the current rows, one per column group
+	part  []*goxls.WorksheetStream
+	parts int
+}
+
+// writeSheet writes the rows returned by nextRow until io.EOF with the settings of sheet. The rows continue on
+// additional worksheets beyond the limits of a worksheet, the worksheets are finished when the rows are written.
+func (ww *workbookWriter) writeSheet(sheet *Csv2XlsConverter, nextRow func() ([]string, error)) error {
+	if err := sheet.numberLocale.Validate(); err != nil {
+		return err
+	}
+
+	rowStyles := make(map[int]goxls.Style)
+	frozenRows := sheet.freezeRows
+	if sheet.header {
+		headerFont := goxls.Font{Bold: true}
+		if sheet.headerFont != nil {
+			headerFont = *sheet.headerFont
+		}
+		rowStyles[0] = goxls.Style{
+			FontIndex:    ww.workbook.AddFont(headerFont),
+			BorderBottom: goxls.BorderThin,
+			FillColor:    sheet.headerFill,
+		}
+		frozenRows = max(frozenRows, 1)
+	} else if sheet.headerFont != nil {
+		rowStyles[0] = goxls.Style{FontIndex: ww.workbook.AddFont(*sheet.headerFont)}
+	}
+
+	widthFitter := newColumnWidthFitter(sheet.maxColumnWidth)
+
+	splitter, err := newColumnSplitter(sheet.keyColumns)
+	if err != nil {
+		return err
+	}
+
+	sheetName := goxls.SanitizeSheetName(sheet.sheetName, DefaultSheetName)
+	if sheetName != sheet.sheetName {
+		ww.converter.logf("Worksheet name %q is not allowed by Excel, %q is used instead", sheet.sheetName, sheetName)
+	}
+
+	// the worksheets of the csv document are ordered by group when the rows are written
+	firstWorksheet := len(ww.worksheets)
+	groups := make([]*worksheetGroup, 0)
+	groupsByValue := make(map[string]*worksheetGroup)
+	splitting := sheet.splitColumn != ""
+	splitColumnIdx := -1
+
+	// headerRow is the first row, it is repeated at the top of the continuation worksheets
+	// and of the worksheets of every value of the split column
+	var headerRow []string
+	// columns are the settings of the columns, the ones of the schema are added when the first row is read
+	columns := schemaColumns{styles: sheet.columnStyles}
+	mismatches := &mismatchReporter{converter: ww.converter}
+	cells := make([]string, 0, goxls.MaxColumns)
+	writeRow := func(ws *goxls.WorksheetStream, columnGroup int, row []string) error {
+		if sheet.columnOverflow == ColumnOverflowSplit {
+			cells = splitter.cells(cells[:0], row, columnGroup)
+			row = cells
+		}
+		err := ws.WriteRow(row)
+		if errors.Is(err, goxls.ErrTypeMismatch) {
+			mismatches.report(ws.Name, err)
+		} else if err != nil {
+			return fmt.Errorf("worksheet %q: %w", ws.Name, err)
+		}
+
+		return nil
+	}
+
+	// addWorksheet adds the worksheet of a column group of the rows starting at rowsPerWorksheet*part
+	addWorksheet := func(g *worksheetGroup, part int, columnGroup int) (*goxls.WorksheetStream, error) {
+		if len(ww.worksheets) == goxls.MaxWorksheets {
+			return nil, goxls.ErrTooManySheets
+		}
+
+		wsName := g.name
+		if part > 0 {
+			wsName = formatContinuationSheetName(sheet.continuationSheetName, g.name, part)
+		}
+
+		wsRowStyles, wsFrozenRows, wsHeaderRows := rowStyles, frozenRows, 1
+		if part > 0 && !sheet.repeatHeader {
+			// the header is the first row of the first worksheet only
+			wsRowStyles, wsFrozenRows, wsHeaderRows = nil, 0, 0
+		}
+
+		wsColumnStyles, wsColumnTypes, wsHiddenColumns := columns.styles, columns.types, columns.hidden
+		var groupColumns []int
+		if columnGroup > 0 {
+			wsName += " col " + goxls.ColumnName(splitter.firstColumn(columnGroup))
+			groupColumns = splitter.columns(columnGroup)
+			wsColumnStyles = remapColumns(columns.styles, groupColumns)
+			wsColumnTypes = remapColumns(columns.types, groupColumns)
+			wsHiddenColumns = remapColumns(columns.hidden, groupColumns)
+		}
+		wsName = ww.names.unique(wsName)
+
+		ws, err := goxls.NewWorksheetStream(goxls.Worksheet{
+			Name:               wsName,
+			DateLayouts:        sheet.dateLayouts,
+			NumberLocale:       sheet.numberLocale,
+			NumberFormat:       sheet.numberFormat,
+			DateFormat:         sheet.dateFormat,
+			DateTimeFormat:     sheet.dateTimeFormat,
+			ColumnStyles:       wsColumnStyles,
+			ColumnTypes:        wsColumnTypes,
+			ColumnTypesFromRow: wsHeaderRows,
+			HiddenColumns:      wsHiddenColumns,
+			RowStyles:          wsRowStyles,
+			FrozenRows:         wsFrozenRows,
+			FrozenColumns:      sheet.freezeColumns,
+		}, &ww.workbook, ww.converter.tempDir)
+		if err != nil {
+			return nil, err
+		}
+		ww.worksheets = append(ww.worksheets, ws)
+		g.worksheets = append(g.worksheets, ws)
+		g.columns = append(g.columns, groupColumns)
+
+		if headerRow != nil && (sheet.repeatHeader || splitting && part == 0) {
+			if err := writeRow(ws, columnGroup, headerRow); err != nil {
+				return nil, err
+			}
+		}
+
+		return ws, nil
+	}
+
+	truncated := false
+	for rowIdx := 0; ; rowIdx++ {
+		row, err := nextRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		columnGroups := 1
+		if len(row) > goxls.MaxColumns {
+			switch sheet.columnOverflow {
+			case ColumnOverflowTruncate:
+				if !truncated {
+					ww.converter.logf("Row %d has %d columns, the columns after %s are dropped", rowIdx+1, len(row), goxls.ColumnName(goxls.MaxColumns-1))
+					truncated = true
+				}
+				row = row[:goxls.MaxColumns]
+			case ColumnOverflowSplit:
+				columnGroups = splitter.groups(len(row))
+			}
+		}
+
+		if sheet.autoColumnWidth {
+			widthFitter.addRow(row)
+		}
+
+		if rowIdx == 0 && sheet.schema != nil {
+			if columns, err = sheet.schema.resolve(row, sheet.columnStyles); err != nil {
+				return err
+			}
+		}
+
+		if rowIdx == 0 && splitting {
+			// the csv reader reuses the slice of the row
+			headerRow = append([]string(nil), row...)
+			for columnIdx, name := range headerRow {
+				if strings.TrimSpace(name) == sheet.splitColumn {
+					splitColumnIdx = columnIdx
+					break
+				}
+			}
+			if splitColumnIdx < 0 {
+				return fmt.Errorf(`split column "%s" is not in the header`, sheet.splitColumn)
+			}
+			// the header is written to the worksheets of every value
+			continue
+		}
+
+		value := ""
+		if splitColumnIdx >= 0 && splitColumnIdx < len(row) {
+			value = row[splitColumnIdx]
+		}
+		g := groupsByValue[value]
+		if g == nil {
+			g = &worksheetGroup{name: sheetName}
+			if splitting {
+				g.name = goxls.SanitizeSheetName(value, blankSheetName)
+			}
+			groups = append(groups, g)
+			// the value shares the memory of the whole row
+			groupsByValue[strings.Clone(value)] = g
+		}
+
+		if len(g.part) > 0 && g.part[0].Rows() == rowsPerWorksheet {
+			g.part = g.part[:0]
+		}
+		if len(g.part) == 0 {
+			ws, err := addWorksheet(g, g.parts, 0)
+			if err != nil {
+				return err
+			}
+			g.part = append(g.part, ws)
+			g.parts++
+		}
+		for columnGroup := len(g.part); columnGroup < columnGroups; columnGroup++ {
+			ws, err := addWorksheet(g, g.parts-1, columnGroup)
+			if err != nil {
+				return err
+			}
+			// the column group starts in this row, the rows above it stay empty
+			if err := ws.SkipRows(g.part[0].Rows() - ws.Rows()); err != nil {
+				return err
+			}
+			g.part = append(g.part, ws)
+		}
+
+		for columnGroup, ws := range g.part {
+			if err := writeRow(ws, columnGroup, row); err != nil {
+				return err
+			}
+		}
+
+		if rowIdx == 0 && sheet.repeatHeader {
+			headerRow = append([]string(nil), row...)
+		}
+	}
+
+	mismatches.summary()
+
+	// an empty csv document, or one with the header of the split column only, gets an empty worksheet
+	if len(groups) == 0 {
+		g := &worksheetGroup{name: sheetName}
+		if _, err := addWorksheet(g, 0, 0); err != nil {
+			return err
+		}
+		groups = append(groups, g)
+	}
+
+	columnWidths := widthFitter.columnWidths
+	for columnIdx, width := range columns.widths {
+		columnWidths[columnIdx] = width
+	}
+	for columnIdx, width := range sheet.columnWidths {
+		columnWidths[columnIdx] = width
+	}
+
+	ww.worksheets = ww.worksheets[:firstWorksheet]
+	for _, g := range groups {
+		ww.worksheets = append(ww.worksheets, g.worksheets...)
+	}
+
+	for _, g := range groups {
+		for i, ws := range g.worksheets {
+			ws.ColumnWidths = columnWidths
+			if g.columns[i] != nil {
+				ws.ColumnWidths = remapColumns(columnWidths, g.columns[i])
+			}
+			if (i == 0 || sheet.repeatHeader) && sheet.autoFilter {
+				ws.AutoFilter = autoFilterRange(ws.Rows(), ws.Columns())
+			}
+			if err := ws.Finish(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeTo writes the workbook globals followed by the worksheets into the OLE container
+func (ww *workbookWriter) writeTo(w io.Writer) error {
+	worksheetSizes := make([]int, 0, len(ww.worksheets))
+	worksheetNames := make([]string, 0, len(ww.worksheets))
+	autoFilters := make([]*goxls.CellRange, 0, len(ww.worksheets))
+	for _, ws := range ww.worksheets {
+		worksheetSizes = append(worksheetSizes, ws.Size())
+		worksheetNames = append(worksheetNames, ws.Name)
+		autoFilters = append(autoFilters, ws.AutoFilter)
+	}
+
+	ww.workbook.WorksheetSizes = worksheetSizes
+	ww.workbook.WorksheetNames = worksheetNames
+	ww.workbook.AutoFilters = autoFilters
+
+	globals, err := ww.workbook.GetWorksheetSizesData()
+	if err != nil {
+		return err
+	}
+	size := len(globals)
+	readers := []io.Reader{strings.NewReader(globals)}
+	for _, ws := range ww.worksheets {
+		size += ws.Size()
+		readers = append(readers, ws.Reader())
+	}
+
+	return ww.converter.writeOle(io.MultiReader(readers...), size, w)
+}
+
+// close removes the temporary files of the worksheets
+func (ww *workbookWriter) close() {
+	for _, ws := range ww.worksheets {
+		_ = ws.Close()
+	}
+}
@@ -0,0 +1,118 @@
+package csv2xls
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestContinuationSheets(t *testing.T) {
+	rows := rowsPerWorksheet + 5
+	tests := []struct {
+		name         string
+		sheetName    string
+		format       string
+		repeatHeader bool
+		wantSheets   []string
+		wantRows     int
+	}{
+		{"default names", DefaultSheetName, DefaultContinuationSheetName, false, []string{"worksheet", "worksheet1"}, 6},
+		{"repeated header", "Data", "{name} ({n})", true, []string{"Data", "Data (2)"}, 7},
+		{"duplicate name", "Data", "{name}", true, []string{"Data", "Data (2)"}, 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewCsv2XlsConverter("", "", ";")
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.WithSheetName(tt.sheetName).WithContinuationSheetName(tt.format).WithRepeatHeader(tt.repeatHeader)
+			sheets := convertTestCsv(t, c, testCsv(rows))
+
+			if names := testSheetNames(sheets); strings.Join(names, ",") != strings.Join(tt.wantSheets, ",") {
+				t.Fatalf("worksheets %q, want %q", names, tt.wantSheets)
+			}
+			if len(sheets[0].rows) != rowsPerWorksheet || len(sheets[1].rows) != tt.wantRows {
+				t.Fatalf("worksheets have %d and %d rows, want %d and %d", len(sheets[0].rows), len(sheets[1].rows), rowsPerWorksheet, tt.wantRows)
+			}
+
+			// the continuation worksheet goes on with the row after the last one of the first worksheet
+			continued := sheets[1].rows
+			if tt.repeatHeader {
+				if strings.Join(continued[0], ";") != "id;name" {
+					t.Errorf("row 0 of %q is %q, want the header", sheets[1].name, continued[0])
+				}
+				continued = continued[1:]
+			}
+			if want := strconv.Itoa(rowsPerWorksheet); continued[0][0] != want {
+				t.Errorf("the first data row of %q has id %q, want %q", sheets[1].name, continued[0][0], want)
+			}
+			if want := strconv.Itoa(rows); continued[len(continued)-1][0] != want {
+				t.Errorf("the last row of %q has id %q, want %q", sheets[1].name, continued[len(continued)-1][0], want)
+			}
+		})
+	}
+}
+
+func TestSplitColumn(t *testing.T) {
+	c, err := NewCsv2XlsConverter("", "", ";")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.WithSplitColumn(" hotel ")
+	sheets := convertTestCsv(t, c, "amount; hotel \n1;A\n2;B\n3;A\n4;\n5;a/b\n6;a:b\n7;History\n8\n")
+
+	// the worksheets are ordered by the first row of every value, each one starts with the header
+	want := []struct {
+		name    string
+		amounts []string
+	}{
+		{"A", []string{"1", "3"}},
+		{"B", []string{"2"}},
+		{"(blank)", []string{"4", "8"}},
+		{"a_b", []string{"5"}},
+		{"a_b (2)", []string{"6"}},
+		{"(blank) (2)", []string{"7"}},
+	}
+	if len(sheets) != len(want) {
+		t.Fatalf("worksheets %q, want %d", testSheetNames(sheets), len(want))
+	}
+	for i, sheet := range sheets {
+		if sheet.name != want[i].name {
+			t.Errorf("worksheet %d is %q, want %q", i, sheet.name, want[i].name)
+		}
+		if strings.Join(sheet.rows[0], ";") != "amount; hotel " {
+			t.Errorf("worksheet %q starts with %q, want the header", sheet.name, sheet.rows[0])
+		}
+		var amounts []string
+		for _, row := range sheet.rows[1:] {
+			amounts = append(amounts, row[0])
+		}
+		if strings.Join(amounts, ",") != strings.Join(want[i].amounts, ",") {
+			t.Errorf("worksheet %q holds the amounts %q, want %q", sheet.name, amounts, want[i].amounts)
+		}
+	}
+}
+
+func TestSplitColumnHeaderOnly(t *testing.T) {
+	c, err := NewCsv2XlsConverter("", "", ";")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sheets := convertTestCsv(t, c.WithSplitColumn("hotel").WithSheetName("Data"), "amount;hotel\n")
+	if len(sheets) != 1 || sheets[0].name != "Data" || len(sheets[0].rows) != 1 || strings.Join(sheets[0].rows[0], ";") != "amount;hotel" {
+		t.Errorf("worksheets %q, want Data with the header only", testSheetNames(sheets))
+	}
+}
+
+func TestSplitColumnNotInHeader(t *testing.T) {
+	c, err := NewCsv2XlsConverter("", "", ";")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.WithSplitColumn("hotel").ConvertReader(strings.NewReader("amount;hotel_id\n1;A\n"), io.Discard)
+	if want := `split column "hotel" is not in the header`; err == nil || err.Error() != want {
+		t.Errorf("ConvertReader() error %v, want %q", err, want)
+	}
+}
@@ -0,0 +1,271 @@
+package csv2xls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// testSheet is a worksheet read back from an xls document, the cells hold the display values of the csv
+type testSheet struct {
+	name   string
+	offset uint32
+	rows   [][]string
+}
+
+// testRecord is a BIFF record of the workbook stream
+type testRecord struct {
+	id   uint16
+	data []byte
+}
+
+// readTestWorkbookStream returns the workbook stream of the OLE container of an xls document
+func readTestWorkbookStream(t *testing.T, xls []byte) []byte {
+	t.Helper()
+	const sectorSize, miniSectorSize = 512, 64
+	if len(xls) < sectorSize || !bytes.HasPrefix(xls, []byte("\xD0\xCF\x11\xE0\xA1\xB1\x1A\xE1")) {
+		t.Fatal("the document is not an OLE container")
+	}
+	le := binary.LittleEndian
+	sector := func(idx uint32) []byte {
+		start := (int(idx) + 1) * sectorSize
+		if start+sectorSize > len(xls) {
+			t.Fatalf("sector %d is beyond the end of the document", idx)
+		}
+		return xls[start : start+sectorSize]
+	}
+
+	// the sectors of the FAT are listed in the header, then in the chain of the extra BDList sectors
+	var fatSectors []uint32
+	fatCount := le.Uint32(xls[0x2C:])
+	for i := 0; i < 109 && uint32(len(fatSectors)) < fatCount; i++ {
+		fatSectors = append(fatSectors, le.Uint32(xls[0x4C+4*i:]))
+	}
+	for next := le.Uint32(xls[0x44:]); uint32(len(fatSectors)) < fatCount; {
+		s := sector(next)
+		for i := 0; i < sectorSize/4-1 && uint32(len(fatSectors)) < fatCount; i++ {
+			fatSectors = append(fatSectors, le.Uint32(s[4*i:]))
+		}
+		next = le.Uint32(s[sectorSize-4:])
+	}
+	var fat []uint32
+	for _, idx := range fatSectors {
+		s := sector(idx)
+		for i := 0; i < sectorSize; i += 4 {
+			fat = append(fat, le.Uint32(s[i:]))
+		}
+	}
+	readChain := func(start uint32) []byte {
+		var data []byte
+		for idx := start; idx != 0xFFFFFFFE; idx = fat[idx] {
+			if int(idx) >= len(fat) || len(data) > len(xls) {
+				t.Fatalf("broken sector chain at %d", idx)
+			}
+			data = append(data, sector(idx)...)
+		}
+		return data
+	}
+
+	directory := readChain(le.Uint32(xls[0x30:]))
+	var root, workbook []byte
+	for i := 0; i+128 <= len(directory); i += 128 {
+		entry := directory[i : i+128]
+		nameLength := int(le.Uint16(entry[64:]))
+		if nameLength < 2 || nameLength > 64 {
+			continue
+		}
+		name := make([]uint16, nameLength/2-1)
+		for j := range name {
+			name[j] = le.Uint16(entry[2*j:])
+		}
+		switch strings.ToLower(string(utf16.Decode(name))) {
+		case "root entry":
+			root = entry
+		case "workbook":
+			workbook = entry
+		}
+	}
+	if root == nil || workbook == nil {
+		t.Fatal("the OLE container has no workbook stream")
+	}
+
+	start, size := le.Uint32(workbook[116:]), int(le.Uint32(workbook[120:]))
+	if size >= int(le.Uint32(xls[0x38:])) {
+		return readChain(start)[:size]
+	}
+
+	// small streams are stored in the mini sectors of the root entry
+	miniStream := readChain(le.Uint32(root[116:]))
+	var miniFat []uint32
+	if miniFatStart := le.Uint32(xls[0x3C:]); miniFatStart != 0xFFFFFFFE {
+		b := readChain(miniFatStart)
+		for i := 0; i < len(b); i += 4 {
+			miniFat = append(miniFat, le.Uint32(b[i:]))
+		}
+	}
+	var data []byte
+	for idx := start; idx != 0xFFFFFFFE; idx = miniFat[idx] {
+		if int(idx) >= len(miniFat) || (int(idx)+1)*miniSectorSize > len(miniStream) {
+			t.Fatalf("broken mini sector chain at %d", idx)
+		}
+		data = append(data, miniStream[int(idx)*miniSectorSize:(int(idx)+1)*miniSectorSize]...)
+	}
+	if len(data) < size {
+		t.Fatalf("workbook stream has %d bytes, want %d", len(data), size)
+	}
+
+	return data[:size]
+}
+
+// readTestRecords reads the records of the workbook stream from offset up to and including the next EOF record
+func readTestRecords(t *testing.T, stream []byte, offset uint32) []testRecord {
+	t.Helper()
+	var records []testRecord
+	for b := stream[offset:]; len(b) >= 4; {
+		id, length := binary.LittleEndian.Uint16(b), int(binary.LittleEndian.Uint16(b[2:]))
+		if len(b) < 4+length {
+			t.Fatalf("record 0x%04X has %d bytes, want %d", id, len(b)-4, length)
+		}
+		records = append(records, testRecord{id, b[4 : 4+length]})
+		if id == 0x000A {
+			return records
+		}
+		b = b[4+length:]
+	}
+	t.Fatalf("no EOF record after offset %d", offset)
+
+	return nil
+}
+
+// readTestSST reads the strings of the SST record and its CONTINUE records
+func readTestSST(records []testRecord) []string {
+	var blocks [][]byte
+	for i, record := range records {
+		if record.id != 0x00FC {
+			continue
+		}
+		blocks = append(blocks, record.data[8:])
+		for _, next := range records[i+1:] {
+			if next.id != 0x003C {
+				break
+			}
+			blocks = append(blocks, next.data)
+		}
+	}
+
+	var strs []string
+	block, pos := 0, 0
+	for block < len(blocks) {
+		if pos >= len(blocks[block]) {
+			block, pos = block+1, 0
+			continue
+		}
+		b := blocks[block]
+		chars, flags := int(binary.LittleEndian.Uint16(b[pos:])), b[pos+2]
+		pos += 3
+		var units []uint16
+		for len(units) < chars {
+			if pos >= len(blocks[block]) {
+				// the characters go on in the next block after the repeated option flags
+				block, pos = block+1, 0
+				flags = blocks[block][0]
+				pos++
+			}
+			if flags&0x01 == 0 {
+				units = append(units, uint16(blocks[block][pos]))
+				pos++
+			} else {
+				units = append(units, binary.LittleEndian.Uint16(blocks[block][pos:]))
+				pos += 2
+			}
+		}
+		strs = append(strs, string(utf16.Decode(units)))
+	}
+
+	return strs
+}
+
+// readTestXLS reads the worksheets of an xls document, the BOUNDSHEET offsets must point at the BOF of the worksheets
+func readTestXLS(t *testing.T, xls []byte) []testSheet {
+	t.Helper()
+	stream := readTestWorkbookStream(t, xls)
+	globals := readTestRecords(t, stream, 0)
+	sst := readTestSST(globals)
+
+	var sheets []testSheet
+	for _, record := range globals {
+		if record.id != 0x0085 {
+			continue
+		}
+		chars := int(record.data[6])
+		name := make([]uint16, chars)
+		for i := range name {
+			name[i] = binary.LittleEndian.Uint16(record.data[8+2*i:])
+		}
+		sheets = append(sheets, testSheet{name: string(utf16.Decode(name)), offset: binary.LittleEndian.Uint32(record.data)})
+	}
+
+	for i := range sheets {
+		records := readTestRecords(t, stream, sheets[i].offset)
+		if records[0].id != 0x0809 || binary.LittleEndian.Uint16(records[0].data[2:]) != 0x0010 {
+			t.Fatalf("worksheet %q at offset %d starts with record 0x%04X, want a worksheet BOF", sheets[i].name, sheets[i].offset, records[0].id)
+		}
+		cell := func(row, col int, value string) {
+			for len(sheets[i].rows) <= row {
+				sheets[i].rows = append(sheets[i].rows, nil)
+			}
+			for len(sheets[i].rows[row]) <= col {
+				sheets[i].rows[row] = append(sheets[i].rows[row], "")
+			}
+			sheets[i].rows[row][col] = value
+		}
+		le := binary.LittleEndian
+		for _, record := range records {
+			d := record.data
+			switch record.id {
+			case 0x00FD: // LABELSST
+				cell(int(le.Uint16(d)), int(le.Uint16(d[2:])), sst[le.Uint32(d[6:])])
+			case 0x0203: // NUMBER
+				cell(int(le.Uint16(d)), int(le.Uint16(d[2:])), formatTestNumber(math.Float64frombits(le.Uint64(d[6:]))))
+			case 0x027E: // RK
+				cell(int(le.Uint16(d)), int(le.Uint16(d[2:])), formatTestNumber(decodeTestRK(le.Uint32(d[6:]))))
+			case 0x00BD: // MULRK
+				row, col := int(le.Uint16(d)), int(le.Uint16(d[2:]))
+				for j := 4; j+6 <= len(d)-2; j += 6 {
+					cell(row, col, formatTestNumber(decodeTestRK(le.Uint32(d[j+2:]))))
+					col++
+				}
+			case 0x0201: // BLANK
+				cell(int(le.Uint16(d)), int(le.Uint16(d[2:])), "")
+			case 0x0205: // BOOLERR
+				cell(int(le.Uint16(d)), int(le.Uint16(d[2:])), strings.ToUpper(strconv.FormatBool(d[6] == 1)))
+			}
+		}
+	}
+
+	return sheets
+}
+
+// decodeTestRK decodes the number of a RK value
+func decodeTestRK(rk uint32) float64 {
+	var num float64
+	if rk&0x02 != 0 {
+		num = float64(int32(rk) >> 2)
+	} else {
+		num = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		num /= 100
+	}
+
+	return num
+}
+
+// formatTestNumber formats a number like it is written in the csv
+func formatTestNumber(num float64) string {
+	return strconv.FormatFloat(num, 'f', -1, 64)
+}
@@ -0,0 +1,127 @@
+package goxls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// testRecord is a BIFF record read back from the bytes of a RecordWriter
+type testRecord struct {
+	id   uint16
+	data []byte
+}
+
+// readTestRecords splits b into its records, the length of every record must match its data
+func readTestRecords(t *testing.T, b []byte) []testRecord {
+	t.Helper()
+	var records []testRecord
+	for len(b) > 0 {
+		if len(b) < 4 {
+			t.Fatalf("%d bytes left after the last record", len(b))
+		}
+		id, length := binary.LittleEndian.Uint16(b), int(binary.LittleEndian.Uint16(b[2:]))
+		if len(b) < 4+length {
+			t.Fatalf("record 0x%04X has %d bytes, want %d", id, len(b)-4, length)
+		}
+		records = append(records, testRecord{id, b[4 : 4+length]})
+		b = b[4+length:]
+	}
+
+	return records
+}
+
+func TestWriteFilterDatabaseName(t *testing.T) {
+	rw := NewRecordWriter(0)
+	wb := newTestWorkbook()
+	wb.writeFilterDatabaseName(rw, 2, CellRange{FirstRow: 0, LastRow: 41, FirstColumn: 1, LastColumn: 4})
+
+	records := readTestRecords(t, rw.Bytes())
+	if len(records) != 1 || records[0].id != 0x0018 {
+		t.Fatalf("writeFilterDatabaseName() wrote %+v, want one NAME record", records)
+	}
+	data := records[0].data
+	if grbit := binary.LittleEndian.Uint16(data); grbit != 0x0021 {
+		t.Errorf("grbit is 0x%04X, want hidden and built-in", grbit)
+	}
+	if itab := binary.LittleEndian.Uint16(data[8:]); itab != 3 {
+		t.Errorf("itab is %d, want the one-based sheet index 3", itab)
+	}
+	if name := data[15]; name != 0x0D {
+		t.Errorf("built-in name is 0x%02X, want _FilterDatabase", name)
+	}
+
+	// the ptgArea3d formula covers the used range of the worksheet
+	wantFormula := []byte{0x3B, 2, 0, 0, 0, 41, 0, 1, 0, 4, 0}
+	if cce := int(binary.LittleEndian.Uint16(data[4:])); cce != len(wantFormula) {
+		t.Errorf("cce is %d, want %d", cce, len(wantFormula))
+	}
+	if formula := data[16:]; !bytes.Equal(formula, wantFormula) {
+		t.Errorf("formula is % x, want % x", formula, wantFormula)
+	}
+}
+
+func TestWriteAutoFilterInfo(t *testing.T) {
+	rw := NewRecordWriter(0)
+	ws := &Worksheet{AutoFilter: &CellRange{LastRow: 9, FirstColumn: 2, LastColumn: 6}}
+	ws.writeAutoFilterInfo(rw)
+
+	records := readTestRecords(t, rw.Bytes())
+	if len(records) != 1 || records[0].id != 0x009D {
+		t.Fatalf("writeAutoFilterInfo() wrote %+v, want one AUTOFILTERINFO record", records)
+	}
+	if cEntries := binary.LittleEndian.Uint16(records[0].data); cEntries != 5 {
+		t.Errorf("cEntries is %d, want 5", cEntries)
+	}
+
+	rw.Reset()
+	(&Worksheet{}).writeAutoFilterInfo(rw)
+	if rw.Len() != 0 {
+		t.Errorf("writeAutoFilterInfo() without an auto filter wrote % x", rw.Bytes())
+	}
+}
+
+func TestWriteMsoDrawing(t *testing.T) {
+	for _, filters := range []int{1, 3} {
+		rw := NewRecordWriter(0)
+		wb := newTestWorkbook()
+		ws := &Worksheet{AutoFilter: &CellRange{LastRow: 9, FirstColumn: 1, LastColumn: filters}}
+		ws.writeMsoDrawing(rw, wb)
+
+		// every drop-down is a MSODRAWING record with its shape followed by its OBJ record
+		records := readTestRecords(t, rw.Bytes())
+		if len(records) != 2*filters {
+			t.Fatalf("%d filters: %d records, want %d", filters, len(records), 2*filters)
+		}
+		var drawing []byte
+		for i, record := range records {
+			switch {
+			case i%2 == 0 && record.id != 0x00EC:
+				t.Fatalf("%d filters: record %d is 0x%04X, want MSODRAWING", filters, i, record.id)
+			case i%2 == 0:
+				drawing = append(drawing, record.data...)
+			case record.id != 0x005D:
+				t.Fatalf("%d filters: record %d is 0x%04X, want OBJ", filters, i, record.id)
+			case len(record.data) != 0x46:
+				t.Errorf("%d filters: OBJ %d has %d bytes, want %d", filters, i/2, len(record.data), 0x46)
+			case binary.LittleEndian.Uint16(record.data[6:]) != uint16(i/2+1):
+				t.Errorf("%d filters: OBJ %d has id %d, want %d", filters, i/2, binary.LittleEndian.Uint16(record.data[6:]), i/2+1)
+			}
+		}
+
+		// the containers span the shapes of all MSODRAWING records
+		if dgLength := int(binary.LittleEndian.Uint32(drawing[4:])); dgLength != 72+96*filters || len(drawing) != 8+dgLength {
+			t.Errorf("%d filters: OfficeArtDgContainer has length %d over %d bytes, want %d", filters, dgLength, len(drawing)-8, 72+96*filters)
+		}
+		spgr := drawing[24:]
+		if recType := binary.LittleEndian.Uint16(spgr[2:]); recType != 0xF003 {
+			t.Fatalf("%d filters: record type 0x%04X, want OfficeArtSpgrContainer", filters, recType)
+		}
+		if spgrLength := int(binary.LittleEndian.Uint32(spgr[4:])); 8+spgrLength != 56+96*filters || len(spgr) != 8+spgrLength {
+			t.Errorf("%d filters: OfficeArtSpgrContainer has length %d over %d bytes, want %d", filters, spgrLength, len(spgr)-8, 48+96*filters)
+		}
+		if len(wb.drawings) != 1 || wb.drawings[0].shapes != uint32(filters+1) {
+			t.Errorf("%d filters: drawings %+v, want one with %d shapes", filters, wb.drawings, filters+1)
+		}
+	}
+}
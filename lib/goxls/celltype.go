@@ -0,0 +1,183 @@
+package goxls
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CellType is the type of the values of a column, see Worksheet.ColumnTypes
+type CellType uint8
+
+const (
+	// CellTypeAuto stores the values that look like numbers or dates as numbers and the others as text
+	CellTypeAuto CellType = iota
+	// CellTypeText stores all values as text, like order numbers that look like numbers
+	CellTypeText
+	// CellTypeInt stores whole numbers like "42" or "007"
+	CellTypeInt
+	// CellTypeDecimal stores numbers like "-1137.494", written in the NumberLocale of the worksheet
+	CellTypeDecimal
+	// CellTypeDate stores dates and times parsed by the layouts of the column
+	CellTypeDate
+	// CellTypeBool stores TRUE or FALSE for "true"/"false", "yes"/"no", "y"/"n" and "1"/"0"
+	CellTypeBool
+	// CellTypePercent stores "12.5%" as 0.125, numbers without a percent sign are fractions already
+	CellTypePercent
+	// CellTypeCurrency stores amounts with or without a currency symbol like "€ 1234.50" or "-$12"
+	CellTypeCurrency
+)
+
+// Default number formats of the typed columns, a column style with a number format overrides them
+const (
+	DefaultPercentFormat  = "0.00%"
+	DefaultCurrencyFormat = "#,##0.00"
+)
+
+// cellTypeNames are the names of the cell types, see ParseCellType
+var cellTypeNames = []string{"auto", "text", "int", "decimal", "date", "bool", "percent", "currency"}
+
+// ParseCellType parses the name of a cell type: "auto", "text", "int", "decimal", "date", "bool", "percent" or "currency"
+func ParseCellType(name string) (CellType, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return CellTypeAuto, nil
+	}
+	for i, typeName := range cellTypeNames {
+		if name == typeName {
+			return CellType(i), nil
+		}
+	}
+
+	return CellTypeAuto, fmt.Errorf(`unknown cell type "%s", expected %s`, name, strings.Join(cellTypeNames, ", "))
+}
+
+// String returns the name of the cell type
+func (t CellType) String() string {
+	if int(t) < len(cellTypeNames) {
+		return cellTypeNames[t]
+	}
+
+	return fmt.Sprintf("CellType(%d)", int(t))
+}
+
+// ColumnType is the type of the values of a column
+type ColumnType struct {
+	Type CellType
+	// DateLayouts are the time layouts of the values of a CellTypeDate column, Worksheet.DateLayouts by default
+	DateLayouts []string
+}
+
+// CellTypeError is a cell with a value that does not match the type of its column, the value is stored as text
+type CellTypeError struct {
+	// Row and Column are the zero-based indexes of the cell
+	Row    int
+	Column int
+	Value  string
+	Type   CellType
+}
+
+// Error ...
+func (e *CellTypeError) Error() string {
+	return fmt.Sprintf("cell %s%d: %q is not a valid %s", ColumnName(e.Column), e.Row+1, e.Value, e.Type)
+}
+
+// Unwrap ...
+func (e *CellTypeError) Unwrap() error {
+	return ErrTypeMismatch
+}
+
+// ColumnName returns the letter reference of the column with zero-based index columnIdx, like "A" or "IV"
+func ColumnName(columnIdx int) string {
+	name := ""
+	for n := columnIdx + 1; n > 0; n = (n - 1) / 26 {
+		name = string(rune('A'+(n-1)%26)) + name
+	}
+
+	return name
+}
+
+// columnType returns the type of the cell, the rows above ColumnTypesFromRow are not typed
+func (ws *Worksheet) columnType(rowIdx int, columnIdx int) (ColumnType, bool) {
+	if rowIdx < ws.ColumnTypesFromRow {
+		return ColumnType{}, false
+	}
+	columnType, ok := ws.ColumnTypes[columnIdx]
+
+	return columnType, ok && columnType.Type != CellTypeAuto
+}
+
+// parseTypedCell converts the value of a cell of a typed column. It returns the number, 1 or 0 for a boolean,
+// with the XF record formatting it, and false when the value does not match the type or the type is text.
+func (ws *Worksheet) parseTypedCell(rowIdx int, columnIdx int, value string, columnType ColumnType, workbook *Workbook) (float64, int, bool) {
+	style := ws.cellStyle(rowIdx, columnIdx)
+	format := ws.NumberFormat
+
+	var num float64
+	var ok bool
+	switch columnType.Type {
+	case CellTypeInt:
+		// whole numbers have no fraction, not even a zero one like in "42.0"
+		if !strings.ContainsRune(value, ws.NumberLocale.decimalSeparator()) {
+			num, ok = ws.NumberLocale.parseNumber(value, false, true)
+		}
+	case CellTypeDecimal:
+		num, ok = ws.NumberLocale.parseNumber(value, false, true)
+	case CellTypePercent:
+		num, ok = parsePercent(value, ws.NumberLocale)
+		format = DefaultPercentFormat
+	case CellTypeCurrency:
+		num, ok = ws.NumberLocale.parseNumber(value, true, true)
+		format = DefaultCurrencyFormat
+	case CellTypeBool:
+		var b bool
+		b, ok = parseBool(value)
+		if b {
+			num = 1
+		}
+		format = ""
+	case CellTypeDate:
+		layouts := columnType.DateLayouts
+		if len(layouts) == 0 {
+			layouts = ws.DateLayouts
+		}
+		var t time.Time
+		var hasClock bool
+		if t, hasClock, ok = parseDate(strings.TrimSpace(value), layouts); ok {
+			num, ok = dateToExcelSerial(t, workbook.Date1904)
+		}
+		format = ws.dateFormat(hasClock)
+	}
+	if !ok {
+		return 0, 0, false
+	}
+
+	if style.NumberFormat == "" {
+		style.NumberFormat = format
+	}
+
+	return num, workbook.AddStyle(style), true
+}
+
+// parsePercent parses "12.5%" as 0.125 and "0.125" as it is
+func parsePercent(value string, locale NumberLocale) (float64, bool) {
+	s := strings.TrimSpace(value)
+	if number, found := strings.CutSuffix(s, "%"); found {
+		num, ok := locale.parseNumber(number, false, true)
+		return num / 100, ok
+	}
+
+	return locale.parseNumber(s, false, true)
+}
+
+// parseBool parses "true"/"false", "yes"/"no", "y"/"n" and "1"/"0" case-insensitively
+func parseBool(value string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "y", "1":
+		return true, true
+	case "false", "no", "n", "0":
+		return false, true
+	}
+
+	return false, false
+}
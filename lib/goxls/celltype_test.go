@@ -0,0 +1,107 @@
+package goxls
+
+import (
+	"testing"
+)
+
+func TestParseTypedCell(t *testing.T) {
+	german := NumberLocale{DecimalSeparator: ',', ThousandsSeparator: '.'}
+	tests := []struct {
+		name       string
+		value      string
+		columnType ColumnType
+		locale     NumberLocale
+		want       float64
+		wantFormat string
+		wantOk     bool
+	}{
+		{"int", "42", ColumnType{Type: CellTypeInt}, NumberLocale{}, 42, "", true},
+		{"int with leading zeros", "007", ColumnType{Type: CellTypeInt}, NumberLocale{}, 7, "", true},
+		{"negative int", "-12", ColumnType{Type: CellTypeInt}, NumberLocale{}, -12, "", true},
+		{"grouped int", "1.234", ColumnType{Type: CellTypeInt}, german, 1234, "", true},
+		{"int with a zero fraction", "42.0", ColumnType{Type: CellTypeInt}, NumberLocale{}, 0, "", false},
+		{"grouped int with a zero fraction", "1,000.00", ColumnType{Type: CellTypeInt}, NumberLocale{DecimalSeparator: '.', ThousandsSeparator: ','}, 0, "", false},
+		{"int with a fraction", "42,5", ColumnType{Type: CellTypeInt}, german, 0, "", false},
+		{"int text", "n/a", ColumnType{Type: CellTypeInt}, NumberLocale{}, 0, "", false},
+		{"decimal", "-1137.494", ColumnType{Type: CellTypeDecimal}, NumberLocale{}, -1137.494, "", true},
+		{"decimal in a locale", "1.137,494", ColumnType{Type: CellTypeDecimal}, german, 1137.494, "", true},
+		{"decimal with leading zeros", "0012.5", ColumnType{Type: CellTypeDecimal}, NumberLocale{}, 12.5, "", true},
+		{"decimal with a currency symbol", "€ 12", ColumnType{Type: CellTypeDecimal}, NumberLocale{}, 0, "", false},
+		{"percent", "12.5%", ColumnType{Type: CellTypePercent}, NumberLocale{}, 0.125, DefaultPercentFormat, true},
+		{"percent fraction", "0.125", ColumnType{Type: CellTypePercent}, NumberLocale{}, 0.125, DefaultPercentFormat, true},
+		{"percent in a locale", "12,5 %", ColumnType{Type: CellTypePercent}, german, 0.125, DefaultPercentFormat, true},
+		{"percent text", "%", ColumnType{Type: CellTypePercent}, NumberLocale{}, 0, "", false},
+		{"currency", "€ 1234.50", ColumnType{Type: CellTypeCurrency}, NumberLocale{}, 1234.5, DefaultCurrencyFormat, true},
+		{"negative currency", "-$12", ColumnType{Type: CellTypeCurrency}, NumberLocale{}, -12, DefaultCurrencyFormat, true},
+		{"currency in parentheses", "(1.234,50 €)", ColumnType{Type: CellTypeCurrency}, german, -1234.5, DefaultCurrencyFormat, true},
+		{"currency with two symbols", "$12€", ColumnType{Type: CellTypeCurrency}, NumberLocale{}, 0, "", false},
+		{"bool true", "Yes", ColumnType{Type: CellTypeBool}, NumberLocale{}, 1, "", true},
+		{"bool false", " 0 ", ColumnType{Type: CellTypeBool}, NumberLocale{}, 0, "", true},
+		{"bool text", "maybe", ColumnType{Type: CellTypeBool}, NumberLocale{}, 0, "", false},
+		{"date", "2024-02-29", ColumnType{Type: CellTypeDate}, NumberLocale{}, 45351, DefaultDateFormat, true},
+		{"date and time", "2024-02-29 12:00:00", ColumnType{Type: CellTypeDate}, NumberLocale{}, 45351.5, DefaultDateTimeFormat, true},
+		{"date of the column layout", "29.02.2024", ColumnType{Type: CellTypeDate, DateLayouts: []string{"02.01.2006"}}, NumberLocale{}, 45351, DefaultDateFormat, true},
+		{"date not of the column layout", "2024-02-29", ColumnType{Type: CellTypeDate, DateLayouts: []string{"02.01.2006"}}, NumberLocale{}, 0, "", false},
+		{"date text", "tomorrow", ColumnType{Type: CellTypeDate}, NumberLocale{}, 0, "", false},
+		{"text", "42", ColumnType{Type: CellTypeText}, NumberLocale{}, 0, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := &Worksheet{DateLayouts: []string{"2006-01-02", "2006-01-02 15:04:05"}, NumberLocale: tt.locale}
+			wb := newTestWorkbook()
+			got, xfIndex, ok := ws.parseTypedCell(0, 0, tt.value, tt.columnType, wb)
+			if ok != tt.wantOk || got != tt.want {
+				t.Fatalf("parseTypedCell(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+
+			format := ""
+			if xfIndex != xfIndexGeneral {
+				format = wb.styles[xfIndex-xfIndexGeneral-1].NumberFormat
+			}
+			if format != tt.wantFormat {
+				t.Errorf("parseTypedCell(%q) is formatted as %q, want %q", tt.value, format, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestParseTypedCellColumnStyle(t *testing.T) {
+	// the number format of the column takes precedence over the default one of the type
+	ws := &Worksheet{ColumnStyles: map[int]Style{2: {NumberFormat: "0.0%"}}}
+	wb := newTestWorkbook()
+	_, xfIndex, ok := ws.parseTypedCell(5, 2, "50%", ColumnType{Type: CellTypePercent}, wb)
+	if !ok {
+		t.Fatal("parseTypedCell() failed")
+	}
+	if format := wb.styles[xfIndex-xfIndexGeneral-1].NumberFormat; format != "0.0%" {
+		t.Errorf("parseTypedCell() is formatted as %q, want %q", format, "0.0%")
+	}
+}
+
+func TestParseCellType(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    CellType
+		wantErr bool
+	}{
+		{"", CellTypeAuto, false},
+		{"auto", CellTypeAuto, false},
+		{" Currency ", CellTypeCurrency, false},
+		{"DATE", CellTypeDate, false},
+		{"number", CellTypeAuto, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseCellType(tt.name)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseCellType(%q) = %s, %v, want %s, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// newTestWorkbook returns a workbook with an empty shared strings table
+func newTestWorkbook() *Workbook {
+	return &Workbook{StringCollection: &StringCollection{StringMap: make(map[string]int)}}
+}
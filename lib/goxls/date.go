@@ -0,0 +1,57 @@
+package goxls
+
+import (
+	"time"
+)
+
+var (
+	// excelEpoch1900 is day 0 of the 1900 date system, shifted by the phantom 1900-02-29 Excel inherited from Lotus 1-2-3
+	excelEpoch1900 = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+	// excelEpoch1904 is day 0 of the 1904 date system
+	excelEpoch1904 = time.Date(1904, time.January, 1, 0, 0, 0, 0, time.UTC)
+	// excelLeapBug1900 is the first day that is shifted by the phantom leap day
+	excelLeapBug1900 = time.Date(1900, time.March, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// parseDate tries each layout in turn. The second result reports whether the matched layout has a time of day.
+func parseDate(value string, layouts []string) (time.Time, bool, bool) {
+	for _, layout := range layouts {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, layoutHasClock(layout), true
+		}
+	}
+
+	return time.Time{}, false, false
+}
+
+// layoutHasClock checks whether a time layout contains hours, minutes or seconds
+func layoutHasClock(layout string) bool {
+	day := time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC)
+	return day.Format(layout) != day.Add(15*time.Hour+4*time.Minute+5*time.Second).Format(layout)
+}
+
+// dateToExcelSerial converts the wall clock of t into an Excel serial date number.
+// Dates outside of the range supported by the date system cannot be stored as numbers.
+func dateToExcelSerial(t time.Time, date1904 bool) (float64, bool) {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	if wall.Year() > 9999 {
+		return 0, false
+	}
+
+	epoch := excelEpoch1904
+	if !date1904 {
+		epoch = excelEpoch1900
+		if wall.Before(excelLeapBug1900) {
+			// 1900-01-01 is serial 1, there is no phantom day to skip yet
+			epoch = epoch.AddDate(0, 0, 1)
+		}
+	}
+
+	serial := float64(wall.Unix()-epoch.Unix())/86400 + float64(wall.Nanosecond())/86400e9
+	if serial < 0 || (!date1904 && serial < 1) {
+		return 0, false
+	}
+
+	return serial, true
+}
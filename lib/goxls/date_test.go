@@ -0,0 +1,62 @@
+package goxls
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	layouts := []string{"2006-01-02", "2006-01-02 15:04:05", "02.01.2006 15:04"}
+	tests := []struct {
+		value        string
+		want         time.Time
+		wantHasClock bool
+		wantOk       bool
+	}{
+		{"2024-02-29", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), false, true},
+		{"2024-02-29 13:45:30", time.Date(2024, time.February, 29, 13, 45, 30, 0, time.UTC), true, true},
+		{"29.02.2024 00:00", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), true, true},
+		{"2023-02-29", time.Time{}, false, false},
+		{"29.02.2024", time.Time{}, false, false},
+		{"2024-02-29T13:45:30", time.Time{}, false, false},
+		{"", time.Time{}, false, false},
+	}
+	for _, tt := range tests {
+		got, hasClock, ok := parseDate(tt.value, layouts)
+		if ok != tt.wantOk || hasClock != tt.wantHasClock || !got.Equal(tt.want) {
+			t.Errorf("parseDate(%q) = %v, %v, %v, want %v, %v, %v", tt.value, got, hasClock, ok, tt.want, tt.wantHasClock, tt.wantOk)
+		}
+	}
+}
+
+func TestDateToExcelSerial(t *testing.T) {
+	tests := []struct {
+		name     string
+		t        time.Time
+		date1904 bool
+		want     float64
+		wantOk   bool
+	}{
+		{"1900 first day", time.Date(1900, time.January, 1, 0, 0, 0, 0, time.UTC), false, 1, true},
+		{"1900 before the phantom leap day", time.Date(1900, time.February, 28, 0, 0, 0, 0, time.UTC), false, 59, true},
+		{"1900 after the phantom leap day", time.Date(1900, time.March, 1, 0, 0, 0, 0, time.UTC), false, 61, true},
+		{"1900 leap day", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), false, 45351, true},
+		{"1900 noon", time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC), false, 45351.5, true},
+		{"1900 wall clock of another zone", time.Date(2024, time.February, 29, 18, 0, 0, 0, time.FixedZone("UTC+5", 5*3600)), false, 45351.75, true},
+		{"1900 last day", time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC), false, 2958465, true},
+		{"1900 before the epoch", time.Date(1899, time.December, 31, 0, 0, 0, 0, time.UTC), false, 0, false},
+		{"1900 after the last day", time.Date(10000, time.January, 1, 0, 0, 0, 0, time.UTC), false, 0, false},
+		{"1904 first day", time.Date(1904, time.January, 1, 0, 0, 0, 0, time.UTC), true, 0, true},
+		{"1904 leap day", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), true, 43889, true},
+		{"1904 noon", time.Date(2024, time.February, 29, 12, 0, 0, 0, time.UTC), true, 43889.5, true},
+		{"1904 before the epoch", time.Date(1903, time.December, 31, 0, 0, 0, 0, time.UTC), true, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := dateToExcelSerial(tt.t, tt.date1904)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("dateToExcelSerial(%v, %v) = %v, %v, want %v, %v", tt.t, tt.date1904, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
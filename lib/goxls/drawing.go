@@ -0,0 +1,43 @@
+package goxls
+
+// CellRange is a rectangular range of cells, the indexes are zero-based and inclusive
+type CellRange struct {
+	FirstRow    int
+	LastRow     int
+	FirstColumn int
+	LastColumn  int
+}
+
+// drawing is the OfficeArt drawing of one worksheet, the shapes are the group shape and its children
+type drawing struct {
+	id     uint32
+	shapes uint32
+}
+
+// firstShapeId returns the identifier of the group shape, every drawing has its own cluster of 1024 identifiers
+func (d drawing) firstShapeId() uint32 {
+	return d.id * 1024
+}
+
+// addDrawing registers the drawing of a worksheet with the number of its child shapes
+func (wb *Workbook) addDrawing(childShapes int) drawing {
+	d := drawing{
+		id:     uint32(len(wb.drawings) + 1),
+		shapes: uint32(childShapes + 1),
+	}
+	wb.drawings = append(wb.drawings, d)
+
+	return d
+}
+
+// putOfficeArtHeader writes the header of an OfficeArt record
+func putOfficeArtHeader(buffer *RecordWriter, version uint16, instance uint16, recType uint16, length uint32) {
+	buffer.PutUint16(version|instance<<4, recType)
+	buffer.PutUint32(length)
+}
+
+// putOfficeArtProperty writes one property of an OfficeArtFOPT record
+func putOfficeArtProperty(buffer *RecordWriter, id uint16, value uint32) {
+	buffer.PutUint16(id)
+	buffer.PutUint32(value)
+}
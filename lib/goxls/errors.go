@@ -0,0 +1,16 @@
+package goxls
+
+import "errors"
+
+var (
+	// ErrTooManyRows is returned when a worksheet gets more than MaxRows rows
+	ErrTooManyRows = errors.New("too many rows, Excel5 has limit to 65536 rows per worksheet. Use XLSX instead")
+	// ErrTooManyColumns is returned when a row has more than MaxColumns cells
+	ErrTooManyColumns = errors.New("too many columns, Excel5 has limit to 256 columns. Use XLSX instead")
+	// ErrTooManySheets is returned when a workbook has more than MaxWorksheets worksheets
+	ErrTooManySheets = errors.New("too many worksheets, Excel5 has limit to 255 worksheets")
+	// ErrInvalidSheetName is returned for a worksheet name Excel refuses, see ValidateSheetName
+	ErrInvalidSheetName = errors.New("invalid worksheet name")
+	// ErrTypeMismatch is returned for the cells that do not match the type of their column, see CellTypeError
+	ErrTypeMismatch = errors.New("value does not match the column type")
+)
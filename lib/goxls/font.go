@@ -0,0 +1,66 @@
+package goxls
+
+// Font describes a FONT record. Empty fields fall back to DefaultFont.
+type Font struct {
+	Name string
+	// Size is the height of the font in points
+	Size      float64
+	Bold      bool
+	Italic    bool
+	Underline bool
+	StrikeOut bool
+	// Color is the palette index of the text, 0 means black
+	Color uint8
+}
+
+// DefaultFont is the font of the cells without a style
+var DefaultFont = Font{Name: "Calibri", Size: 11}
+
+// AddFont returns the index of the FONT record for the font, equal fonts share one record.
+// The font 0 is the default font of the workbook, see Workbook.DefaultFont.
+func (wb *Workbook) AddFont(font Font) uint16 {
+	font = wb.normalizeFont(font)
+	if font == wb.normalizeFont(wb.DefaultFont) {
+		return 0
+	}
+
+	if wb.fontIndexes == nil {
+		wb.fontIndexes = make(map[Font]uint16)
+	}
+	if ifnt, ok := wb.fontIndexes[font]; ok {
+		return ifnt
+	}
+
+	// the FONT records are numbered from 0 omitting 4
+	ifnt := uint16(1 + len(wb.fonts))
+	if ifnt >= 4 {
+		ifnt++
+	}
+	wb.fontIndexes[font] = ifnt
+	wb.fonts = append(wb.fonts, font)
+
+	return ifnt
+}
+
+// normalizeFont fills in the empty fields of the font
+func (wb *Workbook) normalizeFont(font Font) Font {
+	defaultFont := wb.DefaultFont
+	if defaultFont.Name == "" {
+		defaultFont.Name = DefaultFont.Name
+	}
+	if defaultFont.Size == 0 {
+		defaultFont.Size = DefaultFont.Size
+	}
+
+	if font.Name == "" {
+		font.Name = defaultFont.Name
+	}
+	if font.Size == 0 {
+		font.Size = defaultFont.Size
+	}
+	if font.Color == 0 {
+		font.Color = ColorBlack
+	}
+
+	return font
+}
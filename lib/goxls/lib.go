@@ -1,9 +1,7 @@
 package goxls
 
 import (
-	"bytes"
 	"encoding/binary"
-	"fmt"
 	"io"
 	"math"
 	"unicode/utf16"
@@ -20,15 +18,17 @@ func padRight(str, pad string, lenght int) string {
 	}
 }
 
-// PutVar ...
-func PutVar(w io.Writer, args ...interface{}) {
+// PutVar writes the args in little-endian order and returns the first write error.
+//
+// Deprecated: PutVar uses reflection for every value, write the records with a RecordWriter instead.
+func PutVar(w io.Writer, args ...interface{}) error {
 	for _, i := range args {
-		err := binary.Write(w, binary.LittleEndian, i)
-		if err != nil {
-			fmt.Println(err)
-			panic("Cannot write int16")
+		if err := binary.Write(w, binary.LittleEndian, i); err != nil {
+			return err
 		}
 	}
+
+	return nil
 }
 
 // localDateToOLE ...
@@ -42,16 +42,16 @@ func LocalDateToOLE(timestamp int64) string {
 	// lower 4 bytes
 	lowPart := int(math.Floor(((float64(bigDate) / float64(factor)) - float64(highPart)) * float64(factor)))
 
-	buf := new(bytes.Buffer)
+	buf := NewRecordWriter(8)
 	var hex int
 	for i := 0; i < 4; i++ {
 		hex = lowPart % 256
-		PutVar(buf, uint8(hex))
+		buf.PutUint8(uint8(hex))
 		lowPart = int(math.Floor(float64(lowPart) / 256))
 	}
 	for i := 0; i < 4; i++ {
 		hex = int(highPart) % 256
-		PutVar(buf, uint8(hex))
+		buf.PutUint8(uint8(hex))
 		highPart = int64(math.Floor(float64(highPart) / 0x100))
 	}
 
@@ -60,9 +60,9 @@ func LocalDateToOLE(timestamp int64) string {
 
 // ascToUcs utility function to transform ASCII text to Unicode.
 func AsciiToUcs(ascii string) string {
-	buf := new(bytes.Buffer)
+	buf := NewRecordWriter(2 * len(ascii))
 	for i := 0; i < len(ascii); i++ {
-		PutVar(buf, ascii[i], []byte("\x00"))
+		buf.PutUint8(ascii[i], 0x00)
 	}
 
 	return buf.String()
@@ -70,20 +70,21 @@ func AsciiToUcs(ascii string) string {
 
 // utf8toBIFF8UnicodeShort converts a UTF-8 string into BIFF8 Unicode string data (8-bit string length)
 func utf8toBIFF8UnicodeShort(value string) string {
-	buf := new(bytes.Buffer)
-	ln := utf8.RuneCountInString(value)
 	utf16str := utf16.Encode([]rune(value))
-	PutVar(buf, uint8(ln), uint8(0x0001), utf16str)
+	buf := NewRecordWriter(2 + 2*len(utf16str))
+	buf.PutUint8(uint8(utf8.RuneCountInString(value)), 0x0001)
+	buf.PutUint16(utf16str...)
 
 	return buf.String()
 }
 
 // utf8toBIFF8UnicodeLong converts a UTF-8 string into BIFF8 Unicode string data (16-bit string length)
 func Utf8toBIFF8UnicodeLong(value string) string {
-	buf := new(bytes.Buffer)
-	ln := utf8.RuneCountInString(value)
 	utf16str := utf16.Encode([]rune(value))
-	PutVar(buf, uint16(ln), uint8(0x0001), utf16str)
+	buf := NewRecordWriter(3 + 2*len(utf16str))
+	buf.PutUint16(uint16(utf8.RuneCountInString(value)))
+	buf.PutUint8(0x0001)
+	buf.PutUint16(utf16str...)
 
 	return buf.String()
 }
@@ -111,7 +112,3 @@ func minUInt16(x, y uint16) uint16 {
 	}
 	return x
 }
-
-func substr(slice []byte, start, length int) []byte {
-	return slice[start : start+length]
-}
@@ -0,0 +1,198 @@
+package goxls
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NumberLocale is the way the numbers are written, like "1,234.56" or "1.234,56".
+// The zero value detects plain numbers like "1234.56" only.
+type NumberLocale struct {
+	// DecimalSeparator separates the fraction from the whole number, '.' when it is 0
+	DecimalSeparator rune
+	// ThousandsSeparator groups the digits of the whole number by three, the digits are not grouped when
+	// it is 0. A space stands for the no-break spaces too.
+	ThousandsSeparator rune
+}
+
+// locales are the number locales by language, or by language and region when the region writes numbers differently
+var locales = map[string]NumberLocale{
+	"en":    {DecimalSeparator: '.', ThousandsSeparator: ','},
+	"ja":    {DecimalSeparator: '.', ThousandsSeparator: ','},
+	"zh":    {DecimalSeparator: '.', ThousandsSeparator: ','},
+	"de":    {DecimalSeparator: ',', ThousandsSeparator: '.'},
+	"nl":    {DecimalSeparator: ',', ThousandsSeparator: '.'},
+	"es":    {DecimalSeparator: ',', ThousandsSeparator: '.'},
+	"it":    {DecimalSeparator: ',', ThousandsSeparator: '.'},
+	"pt":    {DecimalSeparator: ',', ThousandsSeparator: '.'},
+	"da":    {DecimalSeparator: ',', ThousandsSeparator: '.'},
+	"id":    {DecimalSeparator: ',', ThousandsSeparator: '.'},
+	"tr":    {DecimalSeparator: ',', ThousandsSeparator: '.'},
+	"fr":    {DecimalSeparator: ',', ThousandsSeparator: ' '},
+	"ru":    {DecimalSeparator: ',', ThousandsSeparator: ' '},
+	"uk":    {DecimalSeparator: ',', ThousandsSeparator: ' '},
+	"pl":    {DecimalSeparator: ',', ThousandsSeparator: ' '},
+	"cs":    {DecimalSeparator: ',', ThousandsSeparator: ' '},
+	"sv":    {DecimalSeparator: ',', ThousandsSeparator: ' '},
+	"fi":    {DecimalSeparator: ',', ThousandsSeparator: ' '},
+	"nb":    {DecimalSeparator: ',', ThousandsSeparator: ' '},
+	"de-ch": {DecimalSeparator: '.', ThousandsSeparator: '\''},
+}
+
+// ParseLocale returns the number locale of a language like "de" or a language and region like "en-US" or "de_CH"
+func ParseLocale(name string) (NumberLocale, error) {
+	name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), "_", "-")
+	if name == "" {
+		return NumberLocale{}, nil
+	}
+	if locale, ok := locales[name]; ok {
+		return locale, nil
+	}
+	language, _, _ := strings.Cut(name, "-")
+	if locale, ok := locales[language]; ok {
+		return locale, nil
+	}
+
+	return NumberLocale{}, fmt.Errorf(`unknown locale "%s"`, name)
+}
+
+// Validate checks that the separators can be told apart from each other and from the digits and signs
+func (l NumberLocale) Validate() error {
+	for _, separator := range []rune{l.DecimalSeparator, l.ThousandsSeparator} {
+		if unicode.IsDigit(separator) || strings.ContainsRune("+-()%", separator) {
+			return fmt.Errorf("%q cannot be a number separator", separator)
+		}
+	}
+	if l.ThousandsSeparator != 0 && l.ThousandsSeparator == l.decimalSeparator() {
+		return errors.New("the decimal and the thousands separator must differ")
+	}
+
+	return nil
+}
+
+// isZero tells whether numbers are detected the plain way, see parseNumber
+func (l NumberLocale) isZero() bool {
+	return l == NumberLocale{}
+}
+
+// decimalSeparator ...
+func (l NumberLocale) decimalSeparator() rune {
+	if l.DecimalSeparator == 0 {
+		return '.'
+	}
+
+	return l.DecimalSeparator
+}
+
+// isThousandsSeparator ...
+func (l NumberLocale) isThousandsSeparator(r rune) bool {
+	if l.ThousandsSeparator == ' ' {
+		return r == ' ' || r == '\u00A0' || r == '\u202F'
+	}
+
+	return l.ThousandsSeparator != 0 && r == l.ThousandsSeparator
+}
+
+// parseNumber parses a number written in the locale, like "1.234,56" or "(1,234.56)". The sign goes before
+// the number, a minus may go after it and parentheses around it make it negative. With currency set, a currency
+// symbol may go before or after the number. Leading zeros, like in "007", are refused unless leadingZeros is set.
+func (l NumberLocale) parseNumber(value string, currency bool, leadingZeros bool) (float64, bool) {
+	s := strings.TrimSpace(value)
+	signs, symbols := 0, 0
+	negative := false
+	if len(s) > 2 && s[0] == '(' && s[len(s)-1] == ')' {
+		s = s[1 : len(s)-1]
+		negative = true
+		signs++
+	}
+
+	// the signs and currency symbols around the digits
+	isAffix := func(r rune, trailing bool) bool {
+		switch {
+		case r == '-' || r == '\u2212':
+			negative = true
+			signs++
+		case r == '+' && !trailing:
+			signs++
+		case currency && unicode.Is(unicode.Sc, r):
+			symbols++
+		case unicode.IsSpace(r):
+		default:
+			return false
+		}
+		return true
+	}
+	s = strings.TrimLeftFunc(s, func(r rune) bool { return isAffix(r, false) })
+	s = strings.TrimRightFunc(s, func(r rune) bool { return isAffix(r, true) })
+	if signs > 1 || symbols > 1 {
+		return 0, false
+	}
+
+	decimalSeparator := l.decimalSeparator()
+	normalized := make([]byte, 0, len(s)+1)
+	if negative {
+		normalized = append(normalized, '-')
+	}
+	intDigits, fracDigits, significant := 0, 0, 0
+	// groupDigits are the digits since the last thousands separator, -1 before the first one
+	groupDigits := -1
+	seenPoint := false
+	var firstDigit rune
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			if firstDigit == 0 {
+				firstDigit = r
+			}
+			if seenPoint {
+				fracDigits++
+			} else {
+				intDigits++
+				if groupDigits >= 0 {
+					groupDigits++
+				}
+			}
+			if r != '0' || significant > 0 {
+				significant++
+			}
+			normalized = append(normalized, byte(r))
+		case r == decimalSeparator && !seenPoint:
+			if groupDigits >= 0 && groupDigits != 3 {
+				return 0, false
+			}
+			seenPoint = true
+			normalized = append(normalized, '.')
+		case !seenPoint && l.isThousandsSeparator(r):
+			if intDigits == 0 || groupDigits >= 0 && groupDigits != 3 || groupDigits < 0 && intDigits > 3 {
+				return 0, false
+			}
+			groupDigits = 0
+		default:
+			return 0, false
+		}
+	}
+
+	if intDigits == 0 || (seenPoint && fracDigits == 0) {
+		return 0, false
+	}
+	// leading zeros must be kept, e.g. "007" or "01234"
+	if !leadingZeros && intDigits > 1 && firstDigit == '0' {
+		return 0, false
+	}
+	if !seenPoint && groupDigits >= 0 && groupDigits != 3 {
+		return 0, false
+	}
+	if significant > maxSignificantDigits {
+		return 0, false
+	}
+
+	num, err := strconv.ParseFloat(string(normalized), 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return num, true
+}
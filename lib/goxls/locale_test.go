@@ -0,0 +1,119 @@
+package goxls
+
+import (
+	"testing"
+)
+
+func TestNumberLocaleParseNumber(t *testing.T) {
+	english := NumberLocale{DecimalSeparator: '.', ThousandsSeparator: ','}
+	german := NumberLocale{DecimalSeparator: ',', ThousandsSeparator: '.'}
+	french := NumberLocale{DecimalSeparator: ',', ThousandsSeparator: ' '}
+	swiss := NumberLocale{DecimalSeparator: '.', ThousandsSeparator: '\''}
+	tests := []struct {
+		name         string
+		locale       NumberLocale
+		value        string
+		currency     bool
+		leadingZeros bool
+		want         float64
+		wantOk       bool
+	}{
+		{"plain", NumberLocale{}, "1234.56", false, false, 1234.56, true},
+		{"plain without grouping", NumberLocale{}, "1,234", false, false, 0, false},
+		{"english grouped", english, "1,234,567.89", false, false, 1234567.89, true},
+		{"english not grouped", english, "1234567.89", false, false, 1234567.89, true},
+		{"english group of two", english, "12,34", false, false, 0, false},
+		{"english group of four", english, "1,2345", false, false, 0, false},
+		{"english first group of four", english, "1234,567", false, false, 0, false},
+		{"english leading separator", english, ",123", false, false, 0, false},
+		{"english grouped fraction", english, "1.234,5", false, false, 0, false},
+		{"german", german, "1.234,56", false, false, 1234.56, true},
+		{"german decimal point", german, "1234.56", false, false, 0, false},
+		{"german comma fraction", german, "0,5", false, false, 0.5, true},
+		{"french space", french, "1 234,5", false, false, 1234.5, true},
+		{"french no-break space", french, "1\u00A0234,5", false, false, 1234.5, true},
+		{"french narrow no-break space", french, "1\u202F234\u202F567", false, false, 1234567, true},
+		{"swiss", swiss, "1'234.50", false, false, 1234.5, true},
+		{"negative", english, "-1,234", false, false, -1234, true},
+		{"trailing minus", german, "1.234,50-", false, false, -1234.5, true},
+		{"minus sign", english, "\u22121.5", false, false, -1.5, true},
+		{"plus", english, "+7", false, false, 7, true},
+		{"trailing plus", english, "7+", false, false, 0, false},
+		{"two signs", english, "--7", false, false, 0, false},
+		{"parentheses", english, "(1,234.56)", false, false, -1234.56, true},
+		{"parentheses and a minus", english, "(-5)", false, false, 0, false},
+		{"surrounding spaces", english, "  42 ", false, false, 42, true},
+		{"currency before", english, "$1,234.50", true, false, 1234.5, true},
+		{"currency after", german, "1.234,50 €", true, false, 1234.5, true},
+		{"negative currency", german, "-12,00 €", true, false, -12, true},
+		{"currency in parentheses", english, "($5)", true, false, -5, true},
+		{"currency not allowed", english, "$5", false, false, 0, false},
+		{"two currency symbols", english, "$5€", true, false, 0, false},
+		{"leading zeros", english, "007", false, false, 0, false},
+		{"leading zeros allowed", english, "007", false, true, 7, true},
+		{"zero", english, "0", false, false, 0, true},
+		{"zero fraction", english, "0.25", false, false, 0.25, true},
+		{"no integer digits", english, ".5", false, false, 0, false},
+		{"no fraction digits", english, "5.", false, false, 0, false},
+		{"two decimal separators", english, "1.2.3", false, false, 0, false},
+		{"letters", english, "12a", false, false, 0, false},
+		{"empty", english, "", false, false, 0, false},
+		{"sign only", english, "-", false, false, 0, false},
+		{"15 significant digits", english, "123,456,789,012,345", false, false, 123456789012345, true},
+		{"16 significant digits", english, "1,234,567,890,123,456", false, false, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.locale.parseNumber(tt.value, tt.currency, tt.leadingZeros)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("parseNumber(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    NumberLocale
+		wantErr bool
+	}{
+		{"", NumberLocale{}, false},
+		{"en", NumberLocale{DecimalSeparator: '.', ThousandsSeparator: ','}, false},
+		{"en-US", NumberLocale{DecimalSeparator: '.', ThousandsSeparator: ','}, false},
+		{"de_AT", NumberLocale{DecimalSeparator: ',', ThousandsSeparator: '.'}, false},
+		{"de-CH", NumberLocale{DecimalSeparator: '.', ThousandsSeparator: '\''}, false},
+		{" fr ", NumberLocale{DecimalSeparator: ',', ThousandsSeparator: ' '}, false},
+		{"xx", NumberLocale{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLocale(tt.name)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseLocale(%q) = %+v, %v, want %+v, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestNumberLocaleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		locale  NumberLocale
+		wantErr bool
+	}{
+		{"zero", NumberLocale{}, false},
+		{"german", NumberLocale{DecimalSeparator: ',', ThousandsSeparator: '.'}, false},
+		{"thousands separator only", NumberLocale{ThousandsSeparator: ','}, false},
+		{"same separators", NumberLocale{DecimalSeparator: ',', ThousandsSeparator: ','}, true},
+		{"thousands separator is the default decimal one", NumberLocale{ThousandsSeparator: '.'}, true},
+		{"digit", NumberLocale{DecimalSeparator: '1'}, true},
+		{"sign", NumberLocale{ThousandsSeparator: '-'}, true},
+		{"percent", NumberLocale{DecimalSeparator: '%'}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.locale.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package goxls
+
+import (
+	"math"
+	"strconv"
+)
+
+// maxSignificantDigits is the precision Excel keeps for numbers, longer values are stored as text
+const maxSignificantDigits = 15
+
+// parseNumber detects integer and decimal values like "42", "-7" or "1137.494".
+// Values with a leading zero (postcodes, account numbers) are not treated as numbers.
+func parseNumber(value string) (float64, bool) {
+	s := value
+	if len(s) > 0 && s[0] == '-' {
+		s = s[1:]
+	}
+	if len(s) == 0 {
+		return 0, false
+	}
+
+	intDigits, fracDigits, significant := 0, 0, 0
+	seenPoint := false
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case ch == '.':
+			if seenPoint {
+				return 0, false
+			}
+			seenPoint = true
+		case ch >= '0' && ch <= '9':
+			if seenPoint {
+				fracDigits++
+			} else {
+				intDigits++
+			}
+			if ch != '0' || significant > 0 {
+				significant++
+			}
+		default:
+			return 0, false
+		}
+	}
+
+	if intDigits == 0 || (seenPoint && fracDigits == 0) {
+		return 0, false
+	}
+	// leading zeros must be kept, e.g. "007" or "01234"
+	if intDigits > 1 && s[0] == '0' {
+		return 0, false
+	}
+	if significant > maxSignificantDigits {
+		return 0, false
+	}
+
+	num, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return num, true
+}
+
+// encodeRK packs num into a 32-bit RK value if it can be stored without loss of precision
+func encodeRK(num float64) (uint32, bool) {
+	const minRKInt, maxRKInt = -(1 << 29), 1<<29 - 1
+
+	// 30-bit signed integer
+	if num == math.Trunc(num) && num >= minRKInt && num <= maxRKInt {
+		return uint32(int32(num))<<2 | 0x02, true
+	}
+
+	// 30-bit signed integer divided by 100
+	x100 := math.Round(num * 100)
+	if x100 >= minRKInt && x100 <= maxRKInt && x100/100 == num {
+		return uint32(int32(x100))<<2 | 0x03, true
+	}
+
+	// IEEE 754 number with the 34 least significant bits unset
+	bits := math.Float64bits(num)
+	if bits&(1<<34-1) == 0 {
+		return uint32(bits >> 32), true
+	}
+
+	return 0, false
+}
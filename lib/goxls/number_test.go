@@ -0,0 +1,69 @@
+package goxls
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   float64
+		wantOk bool
+	}{
+		{"42", 42, true},
+		{"-7", -7, true},
+		{"0", 0, true},
+		{"0.5", 0.5, true},
+		{"1137.494", 1137.494, true},
+		{"-1137.494", -1137.494, true},
+		{"123456789012345", 123456789012345, true},
+		{"0.000000000000001", 0.000000000000001, true},
+		{"1234567890123456", 0, false},
+		{"007", 0, false},
+		{"00", 0, false},
+		{"", 0, false},
+		{"-", 0, false},
+		{"1.", 0, false},
+		{".5", 0, false},
+		{"1.2.3", 0, false},
+		{"1e3", 0, false},
+		{"+1", 0, false},
+		{" 1", 0, false},
+		{"1,000", 0, false},
+		{"--1", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseNumber(tt.value)
+		if ok != tt.wantOk || got != tt.want {
+			t.Errorf("parseNumber(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestEncodeRK(t *testing.T) {
+	tests := []struct {
+		num    float64
+		want   uint32
+		wantOk bool
+	}{
+		{0, 0x00000002, true},
+		{1, 0x00000006, true},
+		{-1, 0xFFFFFFFE, true},
+		{1<<29 - 1, 0x7FFFFFFE, true},
+		{-(1 << 29), 0x80000002, true},
+		// out of the integer range, stored as a float without the 34 least significant bits
+		{1 << 29, 0x41C00000, true},
+		{0.5, 0x000000CB, true},
+		{0.1, 0x0000002B, true},
+		{-1.25, 0xFFFFFE0F, true},
+		{1137.494, 0, false},
+		{math.Pi, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := encodeRK(tt.num)
+		if ok != tt.wantOk || got != tt.want {
+			t.Errorf("encodeRK(%v) = 0x%08X, %v, want 0x%08X, %v", tt.num, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
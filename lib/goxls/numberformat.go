@@ -0,0 +1,63 @@
+package goxls
+
+import (
+	"strings"
+)
+
+const (
+	// NumberFormatGeneral is the default number format of the cells
+	NumberFormatGeneral = "General"
+	// DefaultDateFormat is the number format of the date cells when no other format is set
+	DefaultDateFormat = "yyyy-mm-dd"
+	// DefaultDateTimeFormat is the number format of the date and time cells when no other format is set
+	DefaultDateTimeFormat = "yyyy-mm-dd hh:mm:ss"
+
+	// firstCustomNumberFormatIndex is the first index of the FORMAT records, the indexes below are reserved for the built-in formats
+	firstCustomNumberFormatIndex = 164
+)
+
+// builtInNumberFormats are the locale independent built-in formats, they are known to Excel without FORMAT records
+var builtInNumberFormats = map[string]uint16{
+	"0":             1,
+	"0.00":          2,
+	"#,##0":         3,
+	"#,##0.00":      4,
+	"0%":            9,
+	"0.00%":         10,
+	"0.00E+00":      11,
+	"# ?/?":         12,
+	"# ??/??":       13,
+	"h:mm AM/PM":    18,
+	"h:mm:ss AM/PM": 19,
+	"h:mm":          20,
+	"h:mm:ss":       21,
+	"mm:ss":         45,
+	"[h]:mm:ss":     46,
+	"mm:ss.0":       47,
+	"##0.0E+0":      48,
+	"@":             49,
+}
+
+// AddNumberFormat registers a number format code like "#,##0.00" or "dd-mm-yyyy" and returns its index.
+// Built-in formats keep their own index and no FORMAT record is written for them.
+func (wb *Workbook) AddNumberFormat(code string) uint16 {
+	if code == "" || strings.EqualFold(code, NumberFormatGeneral) {
+		return 0
+	}
+	if ifmt, ok := builtInNumberFormats[code]; ok {
+		return ifmt
+	}
+
+	if wb.numberFormatIndexes == nil {
+		wb.numberFormatIndexes = make(map[string]uint16)
+	}
+	if ifmt, ok := wb.numberFormatIndexes[code]; ok {
+		return ifmt
+	}
+
+	ifmt := uint16(firstCustomNumberFormatIndex + len(wb.numberFormats))
+	wb.numberFormatIndexes[code] = ifmt
+	wb.numberFormats = append(wb.numberFormats, code)
+
+	return ifmt
+}
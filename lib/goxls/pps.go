@@ -1,7 +1,7 @@
 package goxls
 
 import (
-	"bytes"
+	"io"
 	"time"
 )
 
@@ -16,31 +16,26 @@ type PPS struct {
 	Data       string
 	Size       uint32
 	StartBlock uint32
+	// Stream is read instead of Data when it is set, Size must be its length then
+	Stream io.Reader
 }
 
 // getPpsWk ...
 func (pps *PPS) GetPpsWk() string {
-	buf := new(bytes.Buffer)
-	PutVar(buf, []byte(padRight(pps.Name, "\x00", 64)))
+	buf := NewRecordWriter(128)
+	buf.PutString(padRight(pps.Name, "\x00", 64))
 
-	PutVar(buf,
-		int16(len(pps.Name)+2),
-		pps.PpsType,
-		int8(0x00),
-		pps.PrevPps,
-		pps.NextPps,
-		pps.DirPps,
-		[]byte("\x00\x09\x02\x00"),
-		[]byte("\x00\x00\x00\x00"),
-		[]byte("\xc0\x00\x00\x00"),
-		[]byte("\x00\x00\x00\x46"),
-		[]byte("\x00\x00\x00\x00"),
-		[]byte(LocalDateToOLE(time.Now().Unix())),
-		[]byte(LocalDateToOLE(time.Now().Unix())),
-		pps.StartBlock,
-		pps.Size,
-		uint32(0),
-	)
+	buf.PutUint16(uint16(len(pps.Name) + 2))
+	buf.PutUint8(pps.PpsType, 0x00)
+	buf.PutUint32(pps.PrevPps, pps.NextPps, pps.DirPps)
+	buf.PutString("\x00\x09\x02\x00")
+	buf.PutString("\x00\x00\x00\x00")
+	buf.PutString("\xc0\x00\x00\x00")
+	buf.PutString("\x00\x00\x00\x46")
+	buf.PutString("\x00\x00\x00\x00")
+	buf.PutString(LocalDateToOLE(time.Now().Unix()))
+	buf.PutString(LocalDateToOLE(time.Now().Unix()))
+	buf.PutUint32(pps.StartBlock, pps.Size, 0)
 
 	return buf.String()
 }
@@ -0,0 +1,129 @@
+package goxls
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// maxRecordDataSize is the maximum length of the data of a BIFF8 record, longer data goes to CONTINUE records
+const maxRecordDataSize = 8224
+
+// recordContinue is the identifier of the CONTINUE record
+const recordContinue uint16 = 0x003C
+
+// RecordWriter writes little-endian BIFF8 records into a growing byte slice without reflection.
+// Records of unknown length are framed by StartRecord and EndRecord, which fills in the length and
+// moves the data beyond the maximum record size to CONTINUE records.
+type RecordWriter struct {
+	buf         []byte
+	recordStart int
+}
+
+// NewRecordWriter returns a writer with a buffer preallocated for size bytes
+func NewRecordWriter(size int) *RecordWriter {
+	return &RecordWriter{
+		buf:         make([]byte, 0, size),
+		recordStart: -1,
+	}
+}
+
+// PutUint8 writes the values as bytes
+func (rw *RecordWriter) PutUint8(values ...uint8) {
+	for _, v := range values {
+		rw.buf = append(rw.buf, v)
+	}
+}
+
+// PutUint16 writes the values as little-endian words
+func (rw *RecordWriter) PutUint16(values ...uint16) {
+	for _, v := range values {
+		rw.buf = binary.LittleEndian.AppendUint16(rw.buf, v)
+	}
+}
+
+// PutUint32 writes the values as little-endian double words
+func (rw *RecordWriter) PutUint32(values ...uint32) {
+	for _, v := range values {
+		rw.buf = binary.LittleEndian.AppendUint32(rw.buf, v)
+	}
+}
+
+// PutFloat64 writes the values as little-endian IEEE 754 doubles
+func (rw *RecordWriter) PutFloat64(values ...float64) {
+	for _, v := range values {
+		rw.buf = binary.LittleEndian.AppendUint64(rw.buf, math.Float64bits(v))
+	}
+}
+
+// PutBytes ...
+func (rw *RecordWriter) PutBytes(b []byte) {
+	rw.buf = append(rw.buf, b...)
+}
+
+// PutString writes the bytes of s as they are, see Utf8toBIFF8UnicodeLong for BIFF8 strings
+func (rw *RecordWriter) PutString(s string) {
+	rw.buf = append(rw.buf, s...)
+}
+
+// PutRecordHeader writes the header of a record with data of a known length
+func (rw *RecordWriter) PutRecordHeader(record uint16, length uint16) {
+	rw.PutUint16(record, length)
+}
+
+// StartRecord writes the header of a record, its length is filled in by EndRecord
+func (rw *RecordWriter) StartRecord(record uint16) {
+	rw.recordStart = len(rw.buf)
+	rw.PutRecordHeader(record, 0)
+}
+
+// EndRecord fills in the length of the record started by StartRecord.
+// The data beyond the maximum record size is split into CONTINUE records.
+func (rw *RecordWriter) EndRecord() {
+	start := rw.recordStart
+	rw.recordStart = -1
+
+	dataStart := start + 4
+	length := len(rw.buf) - dataStart
+	if length <= maxRecordDataSize {
+		binary.LittleEndian.PutUint16(rw.buf[start+2:], uint16(length))
+		return
+	}
+
+	binary.LittleEndian.PutUint16(rw.buf[start+2:], maxRecordDataSize)
+	rest := append([]byte(nil), rw.buf[dataStart+maxRecordDataSize:]...)
+	rw.buf = rw.buf[:dataStart+maxRecordDataSize]
+	for len(rest) > 0 {
+		n := min(len(rest), maxRecordDataSize)
+		rw.PutRecordHeader(recordContinue, uint16(n))
+		rw.PutBytes(rest[:n])
+		rest = rest[n:]
+	}
+}
+
+// Len returns the number of bytes written
+func (rw *RecordWriter) Len() int {
+	return len(rw.buf)
+}
+
+// Bytes returns the bytes written, they are valid until the next write or Reset
+func (rw *RecordWriter) Bytes() []byte {
+	return rw.buf
+}
+
+// String returns the bytes written as a string
+func (rw *RecordWriter) String() string {
+	return string(rw.buf)
+}
+
+// Reset empties the buffer and keeps its capacity
+func (rw *RecordWriter) Reset() {
+	rw.buf = rw.buf[:0]
+	rw.recordStart = -1
+}
+
+// WriteTo writes the bytes written to w
+func (rw *RecordWriter) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(rw.buf)
+	return int64(n), err
+}
@@ -0,0 +1,134 @@
+package goxls
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"testing"
+)
+
+func TestRecordWriterMatchesPutVar(t *testing.T) {
+	want := new(bytes.Buffer)
+	_ = PutVar(want, uint16(0x0203), uint16(0x000E), uint16(7), uint16(3), uint16(15), 1137.494)
+	_ = PutVar(want, uint8(0xFF), uint32(0xFFFFFFFE), []byte("abc"))
+
+	rw := NewRecordWriter(0)
+	rw.PutUint16(0x0203, 0x000E, 7, 3, 15)
+	rw.PutFloat64(1137.494)
+	rw.PutUint8(0xFF)
+	rw.PutUint32(0xFFFFFFFE)
+	rw.PutString("abc")
+
+	if !bytes.Equal(rw.Bytes(), want.Bytes()) {
+		t.Errorf("RecordWriter wrote % x, PutVar % x", rw.Bytes(), want.Bytes())
+	}
+}
+
+func TestRecordWriterEndRecord(t *testing.T) {
+	tests := []struct {
+		name       string
+		dataSize   int
+		wantLength []int
+	}{
+		{"empty", 0, []int{0}},
+		{"short", 10, []int{10}},
+		{"maximum size", maxRecordDataSize, []int{maxRecordDataSize}},
+		{"one continue", maxRecordDataSize + 1, []int{maxRecordDataSize, 1}},
+		{"two continues", 2*maxRecordDataSize + 5, []int{maxRecordDataSize, maxRecordDataSize, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := NewRecordWriter(0)
+			rw.StartRecord(0x00FC)
+			rw.PutBytes(bytes.Repeat([]byte{0xAB}, tt.dataSize))
+			rw.EndRecord()
+
+			b := rw.Bytes()
+			for i, wantLength := range tt.wantLength {
+				wantRecord := recordContinue
+				if i == 0 {
+					wantRecord = 0x00FC
+				}
+				if len(b) < 4 {
+					t.Fatalf("record %d is missing", i)
+				}
+				record, length := binary.LittleEndian.Uint16(b), int(binary.LittleEndian.Uint16(b[2:]))
+				if record != wantRecord || length != wantLength {
+					t.Errorf("record %d is 0x%04X with %d bytes, want 0x%04X with %d bytes", i, record, length, wantRecord, wantLength)
+				}
+				b = b[min(len(b), 4+length):]
+			}
+			if len(b) != 0 {
+				t.Errorf("%d bytes left after the records", len(b))
+			}
+		})
+	}
+}
+
+// benchmarkRows are the values of the NUMBER records the benchmarks write
+const benchmarkRows = 1000
+
+func BenchmarkPutVar(b *testing.B) {
+	buf := new(bytes.Buffer)
+	for range b.N {
+		buf.Reset()
+		for i := 0; i < benchmarkRows; i++ {
+			_ = PutVar(buf, uint16(0x0203), uint16(0x000E), uint16(i), uint16(1), uint16(15), float64(i)+0.5)
+		}
+	}
+}
+
+func BenchmarkRecordWriter(b *testing.B) {
+	rw := NewRecordWriter(benchmarkRows * 18)
+	for range b.N {
+		rw.Reset()
+		for i := 0; i < benchmarkRows; i++ {
+			rw.PutUint16(0x0203, 0x000E, uint16(i), 1, 15)
+			rw.PutFloat64(float64(i) + 0.5)
+		}
+	}
+}
+
+// benchmarkGrid returns rows of text, integer, decimal, date and empty cells
+func benchmarkGrid(rows int) [][]string {
+	grid := make([][]string, 0, rows)
+	for i := 0; i < rows; i++ {
+		grid = append(grid, []string{
+			"row " + strconv.Itoa(i%100),
+			strconv.Itoa(i),
+			strconv.Itoa(i) + ".25",
+			"2024-02-29",
+			"",
+			"text",
+		})
+	}
+
+	return grid
+}
+
+func BenchmarkWorksheetGetData(b *testing.B) {
+	ws := &Worksheet{Name: "worksheet", Grid: benchmarkGrid(benchmarkRows), DateLayouts: []string{"2006-01-02"}}
+	for range b.N {
+		if _, err := ws.GetData(newTestWorkbook()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWorkbookGetWorksheetSizesData(b *testing.B) {
+	ws := &Worksheet{Name: "worksheet", Grid: benchmarkGrid(benchmarkRows), DateLayouts: []string{"2006-01-02"}}
+	wb := newTestWorkbook()
+	data, err := ws.GetData(wb)
+	if err != nil {
+		b.Fatal(err)
+	}
+	wb.WorksheetNames = []string{ws.Name}
+	wb.WorksheetSizes = []int{len(data)}
+
+	b.ResetTimer()
+	for range b.N {
+		if _, err := wb.GetWorksheetSizesData(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package goxls
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxSheetNameLength is the number of characters a worksheet name can hold
+const MaxSheetNameLength = 31
+
+// sheetNameInvalidChars are the characters Excel does not allow in worksheet names
+const sheetNameInvalidChars = `[]:*?/\`
+
+// ValidateSheetName checks the name against the rules of Excel: it has 1 to 31 characters, none of []:*?/\,
+// does not start or end with an apostrophe and is not "History", which Excel reserves
+func ValidateSheetName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: the name is empty", ErrInvalidSheetName)
+	case utf8.RuneCountInString(name) > MaxSheetNameLength:
+		return fmt.Errorf("%w %q: the name is longer than %d characters", ErrInvalidSheetName, name, MaxSheetNameLength)
+	case strings.ContainsAny(name, sheetNameInvalidChars):
+		return fmt.Errorf("%w %q: the name contains one of %s", ErrInvalidSheetName, name, sheetNameInvalidChars)
+	case strings.HasPrefix(name, "'") || strings.HasSuffix(name, "'"):
+		return fmt.Errorf("%w %q: the name starts or ends with an apostrophe", ErrInvalidSheetName, name)
+	case strings.EqualFold(name, "History"):
+		return fmt.Errorf("%w %q: the name is reserved", ErrInvalidSheetName, name)
+	}
+
+	return nil
+}
+
+// SanitizeSheetName returns a valid worksheet name close to name: the invalid characters are replaced by
+// underscores, the apostrophes around it are removed and it is cut to 31 characters.
+// The fallback is returned when nothing is left.
+func SanitizeSheetName(name string, fallback string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(sheetNameInvalidChars, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	name = TruncateSheetName(strings.Trim(name, "' "), MaxSheetNameLength)
+	name = strings.TrimRight(name, "' ")
+	if name == "" || strings.EqualFold(name, "History") {
+		return fallback
+	}
+
+	return name
+}
+
+// TruncateSheetName cuts name to at most length characters
+func TruncateSheetName(name string, length int) string {
+	if utf8.RuneCountInString(name) <= length {
+		return name
+	}
+
+	return string([]rune(name)[:length])
+}
+
+// validateSheetNames checks the names of the worksheets of a workbook, they must be valid and unique case-insensitively
+func validateSheetNames(names []string) error {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if err := ValidateSheetName(name); err != nil {
+			return err
+		}
+		key := strings.ToLower(name)
+		if seen[key] {
+			return fmt.Errorf("%w %q: a worksheet with this name exists already", ErrInvalidSheetName, name)
+		}
+		seen[key] = true
+	}
+
+	return nil
+}
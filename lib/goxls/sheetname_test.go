@@ -0,0 +1,84 @@
+package goxls
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateSheetName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"worksheet", false},
+		{"Données 2024", false},
+		{"it's", false},
+		{strings.Repeat("a", MaxSheetNameLength), false},
+		{strings.Repeat("ä", MaxSheetNameLength), false},
+		{"", true},
+		{strings.Repeat("a", MaxSheetNameLength+1), true},
+		{"a/b", true},
+		{"[x]", true},
+		{"a:b", true},
+		{"a*", true},
+		{"a?", true},
+		{`a\b`, true},
+		{"'quoted", true},
+		{"quoted'", true},
+		{"History", true},
+		{"history", true},
+		{"History 2", false},
+	}
+	for _, tt := range tests {
+		err := ValidateSheetName(tt.name)
+		if (err != nil) != tt.wantErr || err != nil && !errors.Is(err, ErrInvalidSheetName) {
+			t.Errorf("ValidateSheetName(%q) = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSanitizeSheetName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"worksheet", "worksheet"},
+		{"2024/01: sales?", "2024_01_ sales_"},
+		{"'quoted'", "quoted"},
+		{" ' padded ' ", "padded"},
+		{strings.Repeat("a", 40), strings.Repeat("a", MaxSheetNameLength)},
+		{strings.Repeat("日本", 20), strings.Repeat("日本", 15) + "日"},
+		{strings.Repeat("a", 30) + " 'b", strings.Repeat("a", 30)},
+		{"", "fallback"},
+		{"''", "fallback"},
+		{"HISTORY", "fallback"},
+	}
+	for _, tt := range tests {
+		got := SanitizeSheetName(tt.name, "fallback")
+		if got != tt.want {
+			t.Errorf("SanitizeSheetName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if err := ValidateSheetName(got); err != nil {
+			t.Errorf("SanitizeSheetName(%q) = %q is invalid: %v", tt.name, got, err)
+		}
+	}
+}
+
+func TestTruncateSheetName(t *testing.T) {
+	tests := []struct {
+		name   string
+		length int
+		want   string
+	}{
+		{"short", 31, "short"},
+		{"abcdef", 3, "abc"},
+		{"äöüß", 2, "äö"},
+		{"日本語", 3, "日本語"},
+	}
+	for _, tt := range tests {
+		if got := TruncateSheetName(tt.name, tt.length); got != tt.want {
+			t.Errorf("TruncateSheetName(%q, %d) = %q, want %q", tt.name, tt.length, got, tt.want)
+		}
+	}
+}
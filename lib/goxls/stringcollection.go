@@ -11,14 +11,21 @@ type StringCollection struct {
 
 func (sc *StringCollection) AddRow(row []string) {
 	sc.StringGrid = append(sc.StringGrid, row)
-	for _, str := range row {
-		strToSave := Utf8toBIFF8UnicodeLong(str)
-		if _, ok := sc.StringMap[strToSave]; !ok {
-			sc.StringMap[strToSave] = sc.StringUnique
-			sc.StringList = append(sc.StringList, strToSave)
-			sc.StringUnique++
-		}
+}
 
-		sc.StringTotal++
+// AddString registers str in the shared strings table and returns its index.
+// Only the cells written as text go to the table, numbers are stored in the cell records.
+func (sc *StringCollection) AddString(str string) int {
+	strToSave := Utf8toBIFF8UnicodeLong(str)
+	idx, ok := sc.StringMap[strToSave]
+	if !ok {
+		idx = sc.StringUnique
+		sc.StringMap[strToSave] = idx
+		sc.StringList = append(sc.StringList, strToSave)
+		sc.StringUnique++
 	}
+
+	sc.StringTotal++
+
+	return idx
 }
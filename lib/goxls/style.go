@@ -0,0 +1,152 @@
+package goxls
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HorizontalAlignment of the cell content
+type HorizontalAlignment uint8
+
+const (
+	HAlignGeneral HorizontalAlignment = iota
+	HAlignLeft
+	HAlignCenter
+	HAlignRight
+	HAlignFill
+	HAlignJustify
+)
+
+// VerticalAlignment of the cell content
+type VerticalAlignment uint8
+
+const (
+	VAlignBottom VerticalAlignment = iota
+	VAlignTop
+	VAlignCenter
+)
+
+// BorderStyle is the line style of a cell border
+type BorderStyle uint8
+
+const (
+	BorderNone BorderStyle = iota
+	BorderThin
+	BorderMedium
+	BorderDashed
+	BorderDotted
+	BorderThick
+	BorderDouble
+	BorderHair
+)
+
+// Palette color indexes for the style and font colors, see wbPalette
+const (
+	ColorBlack       uint8 = 0x08
+	ColorWhite       uint8 = 0x09
+	ColorRed         uint8 = 0x0A
+	ColorGreen       uint8 = 0x0B
+	ColorBlue        uint8 = 0x0C
+	ColorYellow      uint8 = 0x0D
+	ColorMagenta     uint8 = 0x0E
+	ColorCyan        uint8 = 0x0F
+	ColorDarkRed     uint8 = 0x10
+	ColorDarkGreen   uint8 = 0x11
+	ColorDarkBlue    uint8 = 0x12
+	ColorGray        uint8 = 0x16
+	ColorDarkGray    uint8 = 0x17
+	ColorLightGreen  uint8 = 0x2A
+	ColorLightYellow uint8 = 0x2B
+	ColorLightBlue   uint8 = 0x2C
+	ColorOrange      uint8 = 0x34
+)
+
+var colorNames = map[string]uint8{
+	"black":       ColorBlack,
+	"white":       ColorWhite,
+	"red":         ColorRed,
+	"green":       ColorGreen,
+	"blue":        ColorBlue,
+	"yellow":      ColorYellow,
+	"magenta":     ColorMagenta,
+	"cyan":        ColorCyan,
+	"darkred":     ColorDarkRed,
+	"darkgreen":   ColorDarkGreen,
+	"darkblue":    ColorDarkBlue,
+	"gray":        ColorGray,
+	"grey":        ColorGray,
+	"darkgray":    ColorDarkGray,
+	"darkgrey":    ColorDarkGray,
+	"lightgreen":  ColorLightGreen,
+	"lightyellow": ColorLightYellow,
+	"lightblue":   ColorLightBlue,
+	"orange":      ColorOrange,
+}
+
+// ParseColor returns the palette index of a color name like "red" or "lightblue", or of a palette index from 8 to 63
+func ParseColor(color string) (uint8, error) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if idx, ok := colorNames[strings.NewReplacer("-", "", "_", "", " ", "").Replace(color)]; ok {
+		return idx, nil
+	}
+
+	idx, err := strconv.ParseUint(color, 0, 8)
+	if err != nil || idx < 0x08 || idx > 0x3F {
+		return 0, fmt.Errorf(`unknown color "%s"`, color)
+	}
+
+	return uint8(idx), nil
+}
+
+// Style describes the formatting of a cell. The zero value is the default cell format.
+type Style struct {
+	// FontIndex is the index of the FONT record, 0 is the default font
+	FontIndex uint16
+	// NumberFormat is the format code of the cell, see AddNumberFormat
+	NumberFormat string
+
+	HAlign   HorizontalAlignment
+	VAlign   VerticalAlignment
+	WrapText bool
+
+	BorderLeft   BorderStyle
+	BorderRight  BorderStyle
+	BorderTop    BorderStyle
+	BorderBottom BorderStyle
+	// BorderColor is the palette index of the border lines, 0 means black
+	BorderColor uint8
+
+	// FillColor is the palette index of the solid background, 0 means no fill
+	FillColor uint8
+}
+
+// AddStyle returns the index of the cell XF record with the style. Equal styles share one XF record.
+func (wb *Workbook) AddStyle(style Style) int {
+	if wb.AddNumberFormat(style.NumberFormat) == 0 {
+		// "" and "General" are the same format
+		style.NumberFormat = ""
+	}
+	if style == (Style{}) {
+		return xfIndexGeneral
+	}
+
+	if wb.styleXfIndexes == nil {
+		wb.styleXfIndexes = make(map[Style]int)
+	}
+	if xfIndex, ok := wb.styleXfIndexes[style]; ok {
+		return xfIndex
+	}
+
+	xfIndex := xfIndexGeneral + 1 + len(wb.styles)
+	wb.styleXfIndexes[style] = xfIndex
+	wb.styles = append(wb.styles, style)
+
+	return xfIndex
+}
+
+// hasBorder ...
+func (style Style) hasBorder() bool {
+	return style.BorderLeft != BorderNone || style.BorderRight != BorderNone ||
+		style.BorderTop != BorderNone || style.BorderBottom != BorderNone
+}
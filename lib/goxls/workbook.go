@@ -1,9 +1,7 @@
 package goxls
 
 import (
-	"bytes"
-	"encoding/binary"
-	"fmt"
+	"math"
 	"strings"
 )
 
@@ -75,19 +73,43 @@ var wbPalette = []rgb{
 	{0x3F, 0x33, 0x33, 0x33, 0x00},
 }
 
+// xfIndexGeneral is the cell XF of the default style, the cell XFs of other styles follow it
+const xfIndexGeneral = 15
+
 // Workbook ...
 type Workbook struct {
 	WorksheetSizes   []int
 	WorksheetNames   []string
 	StringCollection *StringCollection
+	// Date1904 switches the workbook to the 1904 date system used by old Mac versions of Excel
+	Date1904 bool
+	// DefaultFont is the font of the cells without a style, goxls.DefaultFont is used when empty
+	DefaultFont Font
+	// AutoFilters are the ranges with filter drop-downs, in the order of WorksheetNames. Nil means no filter.
+	AutoFilters []*CellRange
+
+	fonts               []Font
+	fontIndexes         map[Font]uint16
+	numberFormats       []string
+	numberFormatIndexes map[string]uint16
+	styles              []Style
+	styleXfIndexes      map[Style]int
+	drawings            []drawing
 }
 
-func (wb *Workbook) GetWorksheetSizesData() string {
-	buf := new(bytes.Buffer)
+// GetWorksheetSizesData returns the workbook globals, the records that go before the worksheet substreams
+func (wb *Workbook) GetWorksheetSizesData() (string, error) {
+	buf := NewRecordWriter(4096)
 
 	// Calculate the number of selected worksheet tabs and call the finalization
 	// methods for each worksheet
 	totalWorksheets := len(wb.WorksheetSizes)
+	if totalWorksheets > MaxWorksheets {
+		return "", ErrTooManySheets
+	}
+	if err := validateSheetNames(wb.WorksheetNames); err != nil {
+		return "", err
+	}
 
 	// Add part 1 of the Workbook globals, what goes before the SHEET records
 	wb.storeBof(buf)
@@ -102,7 +124,7 @@ func (wb *Workbook) GetWorksheetSizesData() string {
 	wb.writePalette(buf)
 
 	// Prepare part 3 of the Workbook global stream, what goes after the SHEET records
-	part3Buf := new(bytes.Buffer)
+	part3Buf := NewRecordWriter(4096)
 
 	wb.writeRecalcId(part3Buf)
 
@@ -123,12 +145,12 @@ func (wb *Workbook) GetWorksheetSizesData() string {
 	}
 
 	// Add part 3 of the Workbook globals
-	buf.Write(part3Buf.Bytes())
+	buf.PutBytes(part3Buf.Bytes())
 
-	return buf.String()
+	return buf.String(), nil
 }
 
-func (wb *Workbook) storeBof(buffer *bytes.Buffer) {
+func (wb *Workbook) storeBof(buffer *RecordWriter) {
 	var wbType uint16 = 0x0005
 
 	var record uint16 = 0x0809 // Record identifier    (BIFF5-BIFF8)
@@ -139,22 +161,21 @@ func (wb *Workbook) storeBof(buffer *bytes.Buffer) {
 
 	var version uint16 = 0x0600 //    BIFF8
 
-	PutVar(buffer, record, length)
-	PutVar(buffer, version, wbType, build, year)
+	buffer.PutUint16(record, length, version, wbType, build, year)
 
 	// by inspection of real files, MS Office Excel 2007 writes the following
-	PutVar(buffer, uint32(0x000100D1), uint32(0x00000406))
+	buffer.PutUint32(0x000100D1, 0x00000406)
 }
 
-func (wb *Workbook) writeCodepage(buffer *bytes.Buffer) {
+func (wb *Workbook) writeCodepage(buffer *RecordWriter) {
 	var record uint16 = 0x0042 // Record identifier
 	var length uint16 = 0x0002 // Number of bytes to follow
 	var cv uint16 = 0x04B0     // The code page
 
-	PutVar(buffer, record, length, cv)
+	buffer.PutUint16(record, length, cv)
 }
 
-func (wb *Workbook) writeWindow1(buffer *bytes.Buffer) {
+func (wb *Workbook) writeWindow1(buffer *RecordWriter) {
 	var record uint16 = 0x003D // Record identifier
 	var length uint16 = 0x0012 // Number of bytes to follow
 
@@ -174,21 +195,30 @@ func (wb *Workbook) writeWindow1(buffer *bytes.Buffer) {
 	var itabFirst uint16 = 0 // 1st displayed worksheet
 	var itabCur uint16 = 0   // Active worksheet
 
-	PutVar(buffer, record, length)
-	PutVar(buffer, xWn, yWn, dxWn, dyWn, grbit, itabCur, itabFirst, ctabsel, wTabRatio)
+	buffer.PutUint16(record, length, xWn, yWn, dxWn, dyWn, grbit, itabCur, itabFirst, ctabsel, wTabRatio)
 }
 
-func (wb *Workbook) writeDateMode(buffer *bytes.Buffer) {
+func (wb *Workbook) writeDateMode(buffer *RecordWriter) {
 	var record uint16 = 0x0022 // Record identifier
 	var length uint16 = 0x0002 // Bytes to follow
 
 	var f1904 uint16 = 0 // Flag for 1904 date system
+	if wb.Date1904 {
+		f1904 = 1
+	}
 
-	PutVar(buffer, record, length, f1904)
+	buffer.PutUint16(record, length, f1904)
 }
 
-func (wb *Workbook) writeAllFonts(buffer *bytes.Buffer) {
-	var icv uint16 = 8 // Index to color palette
+func (wb *Workbook) writeAllFonts(buffer *RecordWriter) {
+	wb.writeFont(buffer, wb.normalizeFont(wb.DefaultFont))
+	for _, font := range wb.fonts {
+		wb.writeFont(buffer, font)
+	}
+}
+
+func (wb *Workbook) writeFont(buffer *RecordWriter, font Font) {
+	icv := uint16(font.Color) // Index to color palette
 	var sss uint16 = 0
 
 	var bFamily uint8 = 0     // Font family
@@ -197,48 +227,134 @@ func (wb *Workbook) writeAllFonts(buffer *bytes.Buffer) {
 	var reserved uint8 = 0x00 // Reserved
 	var grbit uint16 = 0x00   // Font attributes
 
-	dataBuf := new(bytes.Buffer)
+	if font.Italic {
+		grbit |= 0x02
+	}
+	if font.StrikeOut {
+		grbit |= 0x08
+	}
+
+	var bls uint16 = 0x190 // Font weight (0x190=400=normal)
+	if font.Bold {
+		bls = 0x2BC // 0x2BC=700=bold
+	}
 
-	var fontSize uint16 = 11
-	PutVar(dataBuf,
-		fontSize*20,
+	var uls uint8 = 0x00 // Underline
+	if font.Underline {
+		uls = 0x01
+	}
+
+	buffer.StartRecord(record)
+	buffer.PutUint16(
+		uint16(math.Round(font.Size*20)),
 		grbit,
-		icv,           // Colour
-		uint16(0x190), // Font weight (0x190=400=normal)
-		sss,           // Superscript/Subscript
-		uint8(0x00),   // Underline
-		bFamily,
-		bCharSet,
-		reserved,
-		[]byte(utf8toBIFF8UnicodeShort("Calibri")),
+		icv, // Colour
+		bls,
+		sss, // Superscript/Subscript
 	)
+	buffer.PutUint8(uls, bFamily, bCharSet, reserved)
+	buffer.PutString(utf8toBIFF8UnicodeShort(font.Name))
+	buffer.EndRecord()
+}
 
-	PutVar(buffer, record, uint16(dataBuf.Len()))
-	buffer.Write(dataBuf.Bytes())
+func (wb *Workbook) writeAllNumberFormats(buffer *RecordWriter) {
+	for i, code := range wb.numberFormats {
+		wb.writeNumberFormat(buffer, uint16(firstCustomNumberFormatIndex+i), code)
+	}
 }
 
-func (wb *Workbook) writeAllNumberFormats(buffer *bytes.Buffer) {
-	// empty
+func (wb *Workbook) writeNumberFormat(buffer *RecordWriter, ifmt uint16, format string) {
+	var record uint16 = 0x041E // Record identifier
+	formatData := Utf8toBIFF8UnicodeLong(format)
+	length := uint16(2 + len(formatData)) // Number of bytes to follow
+
+	buffer.PutUint16(record, length, ifmt)
+	buffer.PutString(formatData)
 }
 
-func (wb *Workbook) writeAllXfs(buffer *bytes.Buffer) {
+func (wb *Workbook) writeAllXfs(buffer *RecordWriter) {
 	var record uint16 = 0x00E0 // Record identifier
 	var length uint16 = 0x0014 // Number of bytes to follow
 
 	for i := 0; i < 15; i++ {
-		PutVar(buffer, record, length)
-		PutVar(buffer, uint16(0), uint16(0), uint16(0xFFF5), uint8(32))
-		PutVar(buffer, uint8(0), uint8(0), uint8(0xC0))
-		PutVar(buffer, uint32(0), uint32(0), uint16(1033))
+		buffer.PutUint16(record, length, 0, 0, 0xFFF5)
+		buffer.PutUint8(32, 0, 0, 0xC0)
+		buffer.PutUint32(0, 0)
+		buffer.PutUint16(1033)
 	}
 
-	PutVar(buffer, record, length)
-	PutVar(buffer, uint16(0), uint16(0), uint16(1), uint8(32))
-	PutVar(buffer, uint8(0), uint8(0), uint8(0xC0))
-	PutVar(buffer, uint32(0), uint32(0), uint16(1033))
+	// Cell XFs
+	wb.writeCellXf(buffer, Style{})
+	for _, style := range wb.styles {
+		wb.writeCellXf(buffer, style)
+	}
 }
 
-func (wb *Workbook) writeAllStyles(buffer *bytes.Buffer) {
+func (wb *Workbook) writeCellXf(buffer *RecordWriter, style Style) {
+	var record uint16 = 0x00E0 // Record identifier
+	var length uint16 = 0x0014 // Number of bytes to follow
+
+	ifnt := style.FontIndex
+	ifmt := wb.AddNumberFormat(style.NumberFormat)
+
+	var fLocked uint16 = 1 // Cell XF, parent is the style XF 0
+
+	// Alignment
+	alc := uint8(style.HAlign)
+	var alcV uint8 = 2 // Bottom
+	switch style.VAlign {
+	case VAlignTop:
+		alcV = 0
+	case VAlignCenter:
+		alcV = 1
+	}
+	var fWrap uint8 = 0
+	if style.WrapText {
+		fWrap = 1
+	}
+	alignment := alc | fWrap<<3 | alcV<<4
+
+	// Borders
+	var borderColor uint32 = 0
+	if style.hasBorder() {
+		borderColor = uint32(ColorBlack)
+		if style.BorderColor != 0 {
+			borderColor = uint32(style.BorderColor)
+		}
+	}
+	border1 := uint32(style.BorderLeft) | uint32(style.BorderRight)<<4 |
+		uint32(style.BorderTop)<<8 | uint32(style.BorderBottom)<<12 |
+		borderColor<<16 | borderColor<<23
+	border2 := borderColor | borderColor<<7
+
+	// Fill
+	var fillColors uint16 = 1033
+	if style.FillColor != 0 {
+		border2 |= 1 << 26 // Solid pattern
+		fillColors = uint16(style.FillColor) | 0x41<<7
+	}
+
+	var usedAttributes uint8 = 0xC0 // Pattern and protection
+	if ifmt != 0 {
+		usedAttributes |= 0x04 // Number format
+	}
+	if ifnt != 0 {
+		usedAttributes |= 0x08 // Font
+	}
+	if alignment != 0x20 {
+		usedAttributes |= 0x10 // Alignment
+	}
+	if style.hasBorder() {
+		usedAttributes |= 0x20 // Border
+	}
+
+	buffer.PutUint16(record, length, ifnt, ifmt, fLocked)
+	buffer.PutUint8(alignment, 0, 0, usedAttributes)
+	buffer.PutUint32(border1, border2)
+	buffer.PutUint16(fillColors)
+}
+
+func (wb *Workbook) writeAllStyles(buffer *RecordWriter) {
 	var record uint16 = 0x0293 // Record identifier
 	var length uint16 = 0x0004 // Bytes to follow
 
@@ -246,124 +362,145 @@ func (wb *Workbook) writeAllStyles(buffer *bytes.Buffer) {
 	var BuiltIn uint8 = 0x00 // Built-in style
 	var iLevel uint8 = 0xff  // Outline style level
 
-	PutVar(buffer, record, length)
-	PutVar(buffer, ixfe, BuiltIn, iLevel)
+	buffer.PutUint16(record, length, ixfe)
+	buffer.PutUint8(BuiltIn, iLevel)
 }
 
-func (wb *Workbook) writePalette(buffer *bytes.Buffer) {
+func (wb *Workbook) writePalette(buffer *RecordWriter) {
 	var record uint16 = 0x0092     // Record identifier
 	length := 2 + 4*len(wbPalette) // Number of bytes to follow
 	ccv := len(wbPalette)          // Number of RGB values to follow
 
-	PutVar(buffer, record, uint16(length), uint16(ccv))
+	buffer.PutUint16(record, uint16(length), uint16(ccv))
 
 	// Pack the RGB data
 	for _, color := range wbPalette {
-		PutVar(buffer, color.red, color.green, color.blue, color.transparent)
+		buffer.PutUint8(color.red, color.green, color.blue, color.transparent)
 	}
 }
 
-func (wb *Workbook) writeRecalcId(buffer *bytes.Buffer) {
+func (wb *Workbook) writeRecalcId(buffer *RecordWriter) {
 	var record uint16 = 0x01C1 // Record identifier
 	var length uint16 = 8      // Number of bytes to follow
 
-	PutVar(buffer, record, length)
+	buffer.PutUint16(record, length)
 
 	// by inspection of real Excel files, MS Office Excel 2007 writes this
-	PutVar(buffer, uint32(0x000001C1), uint32(0x00001E667))
+	buffer.PutUint32(0x000001C1, 0x00001E667)
 }
 
-func (wb *Workbook) writeSupbookInternal(buffer *bytes.Buffer, totalWorksheets int) {
+func (wb *Workbook) writeSupbookInternal(buffer *RecordWriter, totalWorksheets int) {
 	var record uint16 = 0x01AE // Record identifier
 	var length uint16 = 0x0004 // Bytes to follow
 
-	PutVar(buffer, record, length)
-	PutVar(buffer, uint16(totalWorksheets), uint16(0x0401))
+	buffer.PutUint16(record, length, uint16(totalWorksheets), 0x0401)
 }
 
-func (wb *Workbook) writeExternalsheetBiff8(buffer *bytes.Buffer, totalWorksheets int) {
-	if totalWorksheets > 255 {
-		panic("Too many worksheets")
-	}
-
-	tmpBuf := new(bytes.Buffer)
-
+func (wb *Workbook) writeExternalsheetBiff8(buffer *RecordWriter, totalWorksheets int) {
 	cWorksheets := uint16(totalWorksheets)
 
-	var record uint16 = 0x0017            // Record identifier
-	var length uint16 = 2 + 6*cWorksheets // Number of bytes to follow
+	var record uint16 = 0x0017 // Record identifier
 
-	PutVar(tmpBuf, record, length)
-	PutVar(tmpBuf, cWorksheets)
+	buffer.StartRecord(record)
+	buffer.PutUint16(cWorksheets)
 
 	var i uint16
 	for i = 0; i < cWorksheets; i++ {
-		PutVar(tmpBuf, uint16(0x00), i, i)
+		buffer.PutUint16(0x00, i, i)
 	}
 
-	wb.writeData(buffer, tmpBuf)
+	buffer.EndRecord()
 }
 
-func (wb *Workbook) writeAllDefinedNamesBiff8(buffer *bytes.Buffer) {
-	// empty
+func (wb *Workbook) writeAllDefinedNamesBiff8(buffer *RecordWriter) {
+	// Hidden names of the auto filter ranges
+	for i, autoFilter := range wb.AutoFilters {
+		if autoFilter != nil {
+			wb.writeFilterDatabaseName(buffer, i, *autoFilter)
+		}
+	}
 }
 
-func (wb *Workbook) writeMsoDrawingGroup(buffer *bytes.Buffer) {
-	// empty
-}
+// writeFilterDatabaseName writes the built-in _FilterDatabase name of the worksheet with index sheetIdx
+func (wb *Workbook) writeFilterDatabaseName(buffer *RecordWriter, sheetIdx int, cellRange CellRange) {
+	var record uint16 = 0x0018 // Record identifier
+	var length uint16 = 0x001B // Number of bytes to follow
 
-func (wb *Workbook) writeData(bufferTo *bytes.Buffer, bufferFrom *bytes.Buffer) {
-	if bufferFrom.Len()-4 > 8224 {
-		wb.addContinue(bufferTo, bufferFrom)
-	}
+	var grbit uint16 = 0x0021 // Hidden, built-in
+	var chKey uint8 = 0x00    // Keyboard shortcut
+	var cch uint8 = 0x01      // Length of the name
+	var cce uint16 = 0x000B   // Length of the formula
+	var ixals uint16 = 0x0000 // Reserved
+	itab := uint16(sheetIdx + 1)
 
-	bufferTo.Write(bufferFrom.Bytes())
+	var builtInName uint8 = 0x0D // _FilterDatabase
+
+	buffer.PutUint16(record, length, grbit)
+	buffer.PutUint8(chKey, cch)
+	buffer.PutUint16(cce, ixals, itab)
+	buffer.PutUint8(0, 0, 0, 0, 0x00, builtInName)
+
+	// tArea3d formula, the index to EXTERNSHEET is the sheet index
+	buffer.PutUint8(0x3B)
+	buffer.PutUint16(uint16(sheetIdx), uint16(cellRange.FirstRow), uint16(cellRange.LastRow), uint16(cellRange.FirstColumn), uint16(cellRange.LastColumn))
 }
 
-func (wb *Workbook) addContinue(bufferTo *bytes.Buffer, bufferFrom *bytes.Buffer) {
-	var limit uint16 = 8224
-	var record uint16 = 0x003C // Record identifier
+func (wb *Workbook) writeMsoDrawingGroup(buffer *RecordWriter) {
+	if len(wb.drawings) == 0 {
+		return
+	}
 
-	PutVar(bufferTo, substr(bufferFrom.Bytes(), 0, 2), limit, substr(bufferFrom.Bytes(), 4, 8224))
+	var record uint16 = 0x00EB // Record identifier
 
-	bufFromLength := bufferFrom.Len()
+	var spidMax, cspSaved uint32 = 0, 0
+	for _, d := range wb.drawings {
+		spidMax = d.firstShapeId() + d.shapes
+		cspSaved += d.shapes
+	}
+	cdgSaved := uint32(len(wb.drawings))
+	cidcl := cdgSaved + 1
+
+	fdggLength := 16 + 8*cdgSaved
 
-	var i int
-	for i = int(limit + 4); i < (bufFromLength - int(limit)); i += int(limit) {
-		PutVar(bufferTo, record, limit)
-		PutVar(bufferTo, substr(bufferFrom.Bytes(), i, int(limit)))
+	buffer.StartRecord(record)
+	putOfficeArtHeader(buffer, 0xF, 0, 0xF000, 8+fdggLength+26+24) // OfficeArtDggContainer
+	putOfficeArtHeader(buffer, 0x0, 0, 0xF006, fdggLength)         // OfficeArtFDGG
+	buffer.PutUint32(spidMax, cidcl, cspSaved, cdgSaved)
+	for _, d := range wb.drawings {
+		buffer.PutUint32(d.id, d.shapes) // OfficeArtIDCL
 	}
 
-	// Retrieve the last chunk of data
-	PutVar(bufferTo, record, uint16(bufferFrom.Len()-i), bufferFrom.Bytes()[i:])
+	putOfficeArtHeader(buffer, 0x3, 3, 0xF00B, 18)   // OfficeArtFOPT, default shape properties
+	putOfficeArtProperty(buffer, 0x00BF, 0x00080008) // Text
+	putOfficeArtProperty(buffer, 0x0181, 0x08000009) // Fill color
+	putOfficeArtProperty(buffer, 0x01C0, 0x08000040) // Line color
+
+	putOfficeArtHeader(buffer, 0x0, 4, 0xF11E, 16) // OfficeArtSplitMenuColorContainer
+	buffer.PutUint32(0x0800000D, 0x0800000C, 0x08000017, 0x100000F7)
+	buffer.EndRecord()
 }
 
-func (wb *Workbook) writeSharedStringsTable(buffer *bytes.Buffer) {
+func (wb *Workbook) writeSharedStringsTable(buffer *RecordWriter) {
 	// maximum size of record data (excluding record header)
-	continueLimit := 8224
-
-	// initialize array of record data blocks
-	recordDatas := make([]string, 0)
+	continueLimit := maxRecordDataSize
 
-	// start SST record data block with total number of strings, total number of unique strings
+	// the first record data block goes to the SST record, the following ones to CONTINUE records
+	var record uint16 = 0x00FC
 	var recordData strings.Builder
+	storeRecordData := func() {
+		buffer.PutUint16(record, uint16(recordData.Len()))
+		buffer.PutString(recordData.String())
+		record = recordContinue
+	}
 
-	buf := new(bytes.Buffer)
-	PutVar(buf, uint32(wb.StringCollection.StringTotal), uint32(wb.StringCollection.StringUnique))
+	// start SST record data block with total number of strings, total number of unique strings
+	buf := NewRecordWriter(8)
+	buf.PutUint32(uint32(wb.StringCollection.StringTotal), uint32(wb.StringCollection.StringUnique))
 	recordData.Write(buf.Bytes())
 
 	for _, str := range wb.StringCollection.StringList {
-		var length uint16
-		var encoding uint8
-		reader := bytes.NewReader([]byte(str))
-		err1 := binary.Read(reader, binary.LittleEndian, &length)
-		if err1 != nil {
-			fmt.Println("binary.Read failed:", err1)
-		}
-		err2 := binary.Read(reader, binary.LittleEndian, &encoding)
-		if err2 != nil {
-			fmt.Println("binary.Read failed:", err2)
-		}
+		// the option flags follow the 16-bit string length
+		encoding := str[2]
 
 		finished := false
 		for !finished {
@@ -376,7 +513,7 @@ func (wb *Workbook) writeSharedStringsTable(buffer *bytes.Buffer) {
 
 				if recordData.Len()+len(str) == continueLimit {
 					// we close the record data block, and initialize a new one
-					recordDatas = append(recordDatas, recordData.String())
+					storeRecordData()
 					recordData.Reset()
 				}
 
@@ -403,7 +540,7 @@ func (wb *Workbook) writeSharedStringsTable(buffer *bytes.Buffer) {
 				// 1. space remaining is less than minimum space needed
 				if spaceRemaining < minSpaceNeeded {
 					// we close the block, store the block data
-					recordDatas = append(recordDatas, recordData.String())
+					storeRecordData()
 
 					// and start new record data block where we start writing the string
 					recordData.Reset()
@@ -422,7 +559,7 @@ func (wb *Workbook) writeSharedStringsTable(buffer *bytes.Buffer) {
 					recordData.WriteString(str[0:effectiveSpaceRemaining])
 
 					str = str[effectiveSpaceRemaining:] // for next cycle in while loop
-					recordDatas = append(recordDatas, recordData.String())
+					storeRecordData()
 
 					// start new record data block with the repeated option flags
 					recordData.Reset()
@@ -435,29 +572,15 @@ func (wb *Workbook) writeSharedStringsTable(buffer *bytes.Buffer) {
 	// Store the last record data block unless it is empty
 	// if there was no need for any continue records, this will be the for SST record data block itself
 	if recordData.Len() > 0 {
-		recordDatas = append(recordDatas, recordData.String())
-	}
-
-	// combine into one chunk with all the blocks SST, CONTINUE,...
-	for i, rData := range recordDatas {
-		// first block should have the SST record header, remaing should have CONTINUE header
-		var record uint16 = 0x003C
-		if i == 0 {
-			record = 0x00FC
-		}
-
-		tmpBuf := new(bytes.Buffer)
-		PutVar(tmpBuf, record, uint16(len(rData)), []byte(rData))
-
-		wb.writeData(buffer, tmpBuf)
+		storeRecordData()
 	}
 }
 
-func (wb *Workbook) writeEof(buffer *bytes.Buffer) {
+func (wb *Workbook) writeEof(buffer *RecordWriter) {
 	var record uint16 = 0x000A // Record identifier
 	var length uint16 = 0x0000 // Number of bytes to follow
 
-	PutVar(buffer, record, length)
+	buffer.PutUint16(record, length)
 }
 
 func (wb *Workbook) calcSheetOffsets(dataSize int, totalWorksheets int) []uint32 {
@@ -481,7 +604,7 @@ func (wb *Workbook) calcSheetOffsets(dataSize int, totalWorksheets int) []uint32
 	return worksheetOffsets
 }
 
-func (wb *Workbook) writeBoundSheet(buffer *bytes.Buffer, sheetName string, offset uint32) {
+func (wb *Workbook) writeBoundSheet(buffer *RecordWriter, sheetName string, offset uint32) {
 	var record uint16 = 0x0085 // Record identifier
 	var ss uint8 = 0x00
 
@@ -491,7 +614,8 @@ func (wb *Workbook) writeBoundSheet(buffer *bytes.Buffer, sheetName string, offs
 	biff8SheetName := utf8toBIFF8UnicodeShort(sheetName)
 	length := 6 + len(biff8SheetName)
 
-	PutVar(buffer, record, uint16(length))
-	PutVar(buffer, offset, ss, st)
-	PutVar(buffer, []byte(biff8SheetName))
+	buffer.PutUint16(record, uint16(length))
+	buffer.PutUint32(offset)
+	buffer.PutUint8(ss, st)
+	buffer.PutString(biff8SheetName)
 }
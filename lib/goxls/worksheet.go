@@ -1,7 +1,8 @@
 package goxls
 
 import (
-	"bytes"
+	"errors"
+	"fmt"
 )
 
 // Worksheet ...
@@ -9,20 +10,82 @@ type Worksheet struct {
 	Name         string
 	Grid         [][]string
 	ColumnWidths map[int]int
+	// DateLayouts are the time layouts of the values stored as dates, see time.Parse
+	DateLayouts []string
+	// NumberLocale is the way the numbers are written, see NumberLocale
+	NumberLocale NumberLocale
+	// NumberFormat is the format code of the numeric cells, General by default
+	NumberFormat string
+	// DateFormat is the format code of the date cells, DefaultDateFormat by default
+	DateFormat string
+	// DateTimeFormat is the format code of the date and time cells, DefaultDateTimeFormat by default
+	DateTimeFormat string
+	// ColumnStyles are the styles of the cells per column. The number format of a column style
+	// overrides NumberFormat, DateFormat and DateTimeFormat.
+	ColumnStyles map[int]Style
+	// ColumnTypes are the types of the cells per column, the columns without a type are CellTypeAuto
+	ColumnTypes map[int]ColumnType
+	// ColumnTypesFromRow is the first row ColumnTypes apply to, the rows above are headers
+	ColumnTypesFromRow int
+	// HiddenColumns are the columns that are not shown
+	HiddenColumns map[int]bool
+	// RowStyles are the styles of the cells per row, they take precedence over ColumnStyles
+	RowStyles map[int]Style
+	// FrozenRows is the number of rows at the top that stay visible while scrolling
+	FrozenRows int
+	// FrozenColumns is the number of columns on the left that stay visible while scrolling
+	FrozenColumns int
+	// AutoFilter is the range with filter drop-downs in its first row. The same range must be
+	// set in Workbook.AutoFilters for the worksheet. The filters have no criteria, no rows are hidden.
+	AutoFilter *CellRange
 }
 
 func (ws *Worksheet) GetName() string {
 	return ws.Name
 }
 
-func (ws *Worksheet) GetData(stringCollection *StringCollection) string {
-	buf := new(bytes.Buffer)
+// GetData returns the worksheet substream of the Grid
+func (ws *Worksheet) GetData(workbook *Workbook) (string, error) {
+	buf := NewRecordWriter(4096)
 
 	maxColIdx := 0
 	for _, row := range ws.Grid {
 		maxColIdx = max(maxColIdx, len(row)-1)
 	}
 
+	ws.writeHead(buf, workbook, len(ws.Grid), maxColIdx)
+
+	// Write Cells
+	for rowIdx, rows := range ws.Grid {
+		if err := checkRow(rowIdx, rows); err != nil {
+			return "", err
+		}
+
+		// the cells that do not match the type of their column are stored as text
+		if err := ws.writeRow(buf, rowIdx, rows, workbook); err != nil && !errors.Is(err, ErrTypeMismatch) {
+			return "", err
+		}
+	}
+
+	ws.writeTail(buf, workbook)
+
+	return buf.String(), nil
+}
+
+// checkRow returns an error when the row with zero-based index rowIdx does not fit into a worksheet
+func checkRow(rowIdx int, row []string) error {
+	if rowIdx >= MaxRows {
+		return ErrTooManyRows
+	}
+	if len(row) > MaxColumns {
+		return fmt.Errorf("row %d has %d columns: %w", rowIdx+1, len(row), ErrTooManyColumns)
+	}
+
+	return nil
+}
+
+// writeHead writes the records that go before the cells of a worksheet with rows rows and maxColIdx+1 columns
+func (ws *Worksheet) writeHead(buf *RecordWriter, workbook *Workbook, rows int, maxColIdx int) {
 	// Write BOF record
 	ws.storeBof(buf)
 
@@ -41,7 +104,11 @@ func (ws *Worksheet) GetData(stringCollection *StringCollection) string {
 		if value, ok := ws.ColumnWidths[i]; ok {
 			w = value
 		}
-		var info = []uint16{uint16(i), uint16(i), uint16(w), 15, 0, 0}
+		var grbit uint16
+		if ws.HiddenColumns[i] {
+			grbit = 0x0001
+		}
+		var info = []uint16{uint16(i), uint16(i), uint16(w), uint16(workbook.AddStyle(ws.ColumnStyles[i])), grbit, 0}
 		columnInfo = append(columnInfo, info)
 	}
 
@@ -90,32 +157,22 @@ func (ws *Worksheet) GetData(stringCollection *StringCollection) string {
 		}
 	}
 
+	// Write AUTOFILTERINFO record, there is no FILTERMODE record as no rows are hidden by filter criteria
+	ws.writeAutoFilterInfo(buf)
+
 	// Write sheet dimensions
 	var firstRowIndex uint32 = 0
-	lastRowIndex := uint32(len(ws.Grid))
+	lastRowIndex := uint32(rows)
 	var firstColumnIndex uint16 = 1
 	var lastColumnIndex uint16 = uint16(maxColIdx) + 1
 
 	ws.writeDimensions(buf, firstRowIndex, lastRowIndex, firstColumnIndex, lastColumnIndex)
+}
 
-	// Write Cells
-	for rowIdx, rows := range ws.Grid {
-		for columnIdx, cValue := range rows {
-			if rowIdx > 65535 || columnIdx > 255 {
-				panic("Rows or columns overflow! Excel5 has limit to 65535 rows and 255 columns. Use XLSX instead.")
-			}
-
-			// Write cell value
-			if cValue == "" {
-				ws.writeBlank(buf, rowIdx, columnIdx, 15)
-			} else {
-				ws.writeString(buf, rowIdx, columnIdx, cValue, 15, stringCollection)
-			}
-		}
-	}
-
+// writeTail writes the records that go after the cells of a worksheet
+func (ws *Worksheet) writeTail(buf *RecordWriter, workbook *Workbook) {
 	// Append
-	ws.writeMsoDrawing(buf)
+	ws.writeMsoDrawing(buf, workbook)
 
 	// Write WINDOW2 record
 	ws.writeWindow2(buf)
@@ -126,6 +183,9 @@ func (ws *Worksheet) GetData(stringCollection *StringCollection) string {
 	// Write ZOOM record
 	ws.writeZoom(buf)
 
+	// Write PANE record
+	ws.writePanes(buf)
+
 	// Write SELECTION record
 	ws.writeSelection(buf)
 
@@ -140,11 +200,9 @@ func (ws *Worksheet) GetData(stringCollection *StringCollection) string {
 	ws.writeRangeProtection(buf)
 
 	ws.storeEof(buf)
-
-	return buf.String()
 }
 
-func (ws *Worksheet) storeBof(buffer *bytes.Buffer) {
+func (ws *Worksheet) storeBof(buffer *RecordWriter) {
 	var bType uint16 = 0x0010
 
 	var record uint16 = 0x0809 // Record identifier    (BIFF5-BIFF8)
@@ -154,40 +212,40 @@ func (ws *Worksheet) storeBof(buffer *bytes.Buffer) {
 	var year uint16 = 0x07CC    //    Excel 97
 	var version uint16 = 0x0600 //    BIFF8
 
-	PutVar(buffer, record, length, version, bType, build, year)
+	buffer.PutUint16(record, length, version, bType, build, year)
 
 	// by inspection of real files, MS Office Excel 2007 writes the following
-	PutVar(buffer, uint32(0x000100D1), uint32(0x00000406))
+	buffer.PutUint32(0x000100D1, 0x00000406)
 }
 
-func (ws *Worksheet) writePrintHeaders(buffer *bytes.Buffer) {
+func (ws *Worksheet) writePrintHeaders(buffer *RecordWriter) {
 	var record uint16 = 0x002a // Record identifier
 	var length uint16 = 0x0002 // Bytes to follow
 
 	var fPrintRwCol uint16 = 0 // Boolean flag
 
-	PutVar(buffer, record, length, fPrintRwCol)
+	buffer.PutUint16(record, length, fPrintRwCol)
 }
 
-func (ws *Worksheet) writePrintGridlines(buffer *bytes.Buffer) {
+func (ws *Worksheet) writePrintGridlines(buffer *RecordWriter) {
 	var record uint16 = 0x002b // Record identifier
 	var length uint16 = 0x0002 // Bytes to follow
 
 	var fPrintGrid uint16 = 0 // Boolean flag
 
-	PutVar(buffer, record, length, fPrintGrid)
+	buffer.PutUint16(record, length, fPrintGrid)
 }
 
-func (ws *Worksheet) writeGridset(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeGridset(buffer *RecordWriter) {
 	var record uint16 = 0x0082 // Record identifier
 	var length uint16 = 0x0002 // Bytes to follow
 
 	var fGridSet uint16 = 1 // Boolean flag
 
-	PutVar(buffer, record, length, fGridSet)
+	buffer.PutUint16(record, length, fGridSet)
 }
 
-func (ws *Worksheet) writeGuts(buffer *bytes.Buffer, columnInfo [][]uint16) {
+func (ws *Worksheet) writeGuts(buffer *RecordWriter, columnInfo [][]uint16) {
 	var record uint16 = 0x0080 // Record identifier
 	var length uint16 = 0x0008 // Bytes to follow
 
@@ -213,15 +271,14 @@ func (ws *Worksheet) writeGuts(buffer *bytes.Buffer, columnInfo [][]uint16) {
 		col_level++
 	}
 
-	PutVar(buffer, record, length)
-	PutVar(buffer, dxRwGut, dxColGut, maxRowOutlineLevel, col_level)
+	buffer.PutUint16(record, length, dxRwGut, dxColGut, maxRowOutlineLevel, col_level)
 }
 
-func (ws *Worksheet) writeDefaultRowHeight(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeDefaultRowHeight(buffer *RecordWriter) {
 	// empty
 }
 
-func (ws *Worksheet) writeWsbool(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeWsbool(buffer *RecordWriter) {
 	var record uint16 = 0x0081 // Record identifier
 	var length uint16 = 0x0002 // Bytes to follow
 	var grbit uint16 = 0x0000
@@ -232,84 +289,90 @@ func (ws *Worksheet) writeWsbool(buffer *bytes.Buffer) {
 	grbit |= 0x0080 // Outline summary right
 	grbit |= 0x0400 // Outline symbols displayed
 
-	PutVar(buffer, record, length, grbit)
+	buffer.PutUint16(record, length, grbit)
 }
 
-func (ws *Worksheet) writeBreaks(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeBreaks(buffer *RecordWriter) {
 	// empty
 }
 
-func (ws *Worksheet) writeHeader(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeHeader(buffer *RecordWriter) {
 	var record uint16 = 0x0014 // Record identifier
 	recordData := Utf8toBIFF8UnicodeLong("")
 	length := uint16(len(recordData))
 
-	PutVar(buffer, record, length, []byte(recordData))
+	buffer.PutUint16(record, length)
+	buffer.PutString(recordData)
 }
 
-func (ws *Worksheet) writeFooter(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeFooter(buffer *RecordWriter) {
 	var record uint16 = 0x0015 // Record identifier
 	recordData := Utf8toBIFF8UnicodeLong("")
 	length := uint16(len(recordData))
 
-	PutVar(buffer, record, length, []byte(recordData))
+	buffer.PutUint16(record, length)
+	buffer.PutString(recordData)
 }
 
-func (ws *Worksheet) writeHcenter(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeHcenter(buffer *RecordWriter) {
 	var record uint16 = 0x0083 // Record identifier
 	var length uint16 = 0x0002 // Bytes to follow
 
 	var fHCenter uint16 = 0 // Horizontal centering
 
-	PutVar(buffer, record, length, fHCenter)
+	buffer.PutUint16(record, length, fHCenter)
 }
 
-func (ws *Worksheet) writeVcenter(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeVcenter(buffer *RecordWriter) {
 	var record uint16 = 0x0084 // Record identifier
 	var length uint16 = 0x0002 // Bytes to follow
 
 	var fVCenter uint16 = 0 // Horizontal centering
 
-	PutVar(buffer, record, length, fVCenter)
+	buffer.PutUint16(record, length, fVCenter)
 }
 
-func (ws *Worksheet) writeMarginLeft(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeMarginLeft(buffer *RecordWriter) {
 	var record uint16 = 0x0026 // Record identifier
 	var length uint16 = 0x0008 // Bytes to follow
 
 	margin := 0.7 // Margin in inches
 
-	PutVar(buffer, record, length, margin)
+	buffer.PutUint16(record, length)
+	buffer.PutFloat64(margin)
 }
 
-func (ws *Worksheet) writeMarginRight(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeMarginRight(buffer *RecordWriter) {
 	var record uint16 = 0x0027 // Record identifier
 	var length uint16 = 0x0008 // Bytes to follow
 
 	margin := 0.7 // Margin in inches
 
-	PutVar(buffer, record, length, margin)
+	buffer.PutUint16(record, length)
+	buffer.PutFloat64(margin)
 }
 
-func (ws *Worksheet) writeMarginTop(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeMarginTop(buffer *RecordWriter) {
 	var record uint16 = 0x0028 // Record identifier
 	var length uint16 = 0x0008 // Bytes to follow
 
 	margin := 0.75 // Margin in inches
 
-	PutVar(buffer, record, length, margin)
+	buffer.PutUint16(record, length)
+	buffer.PutFloat64(margin)
 }
 
-func (ws *Worksheet) writeMarginBottom(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeMarginBottom(buffer *RecordWriter) {
 	var record uint16 = 0x0029 // Record identifier
 	var length uint16 = 0x0008 // Bytes to follow
 
 	margin := 0.75 // Margin in inches
 
-	PutVar(buffer, record, length, margin)
+	buffer.PutUint16(record, length)
+	buffer.PutFloat64(margin)
 }
 
-func (ws *Worksheet) writeSetup(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeSetup(buffer *RecordWriter) {
 	var record uint16 = 0x00A1 // Record identifier
 	var length uint16 = 0x0022 // Number of bytes to follow
 
@@ -349,38 +412,37 @@ func (ws *Worksheet) writeSetup(buffer *bytes.Buffer) {
 	grbit |= fNoOrient << 6
 	grbit |= fUsePage << 7
 
-	PutVar(buffer, record, length)
-	PutVar(buffer, iPaperSize, iScale, iPageStart, iFitWidth, iFitHeight, grbit, iRes, iVRes)
-	PutVar(buffer, numHdr, numFtr)
-	PutVar(buffer, iCopies)
+	buffer.PutUint16(record, length, iPaperSize, iScale, iPageStart, iFitWidth, iFitHeight, grbit, iRes, iVRes)
+	buffer.PutFloat64(numHdr, numFtr)
+	buffer.PutUint16(iCopies)
 }
 
-func (ws *Worksheet) writeProtect(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeProtect(buffer *RecordWriter) {
 	// empty
 }
 
-func (ws *Worksheet) writeScenProtect(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeScenProtect(buffer *RecordWriter) {
 	// empty
 }
 
-func (ws *Worksheet) writeObjectProtect(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeObjectProtect(buffer *RecordWriter) {
 	// empty
 }
 
-func (ws *Worksheet) writePassword(buffer *bytes.Buffer) {
+func (ws *Worksheet) writePassword(buffer *RecordWriter) {
 	// empty
 }
 
-func (ws *Worksheet) writeDefcol(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeDefcol(buffer *RecordWriter) {
 	var defaultColWidth uint16 = 8
 
 	var record uint16 = 0x0055 // Record identifier
 	var length uint16 = 0x0002 // Number of bytes to follow
 
-	PutVar(buffer, record, length, defaultColWidth)
+	buffer.PutUint16(record, length, defaultColWidth)
 }
 
-func (ws *Worksheet) writeColinfo(buffer *bytes.Buffer, columnInfo []uint16) {
+func (ws *Worksheet) writeColinfo(buffer *RecordWriter, columnInfo []uint16) {
 	var colFirst, colLast, grbit, level uint16
 	var coldx uint16 = 10
 	var xfIndex uint16 = 15
@@ -418,45 +480,289 @@ func (ws *Worksheet) writeColinfo(buffer *bytes.Buffer, columnInfo []uint16) {
 	level = maxUInt16(0, minUInt16(level, 7))
 	grbit |= level << 8
 
-	PutVar(buffer, record, length)
-	PutVar(buffer, colFirst, colLast, coldx, ixfe, grbit, reserved)
+	buffer.PutUint16(record, length, colFirst, colLast, coldx, ixfe, grbit, reserved)
 }
 
-func (ws *Worksheet) writeDimensions(buffer *bytes.Buffer, firstRowIndex uint32, lastRowIndex uint32, firstColumnIndex uint16, lastColumnIndex uint16) {
+func (ws *Worksheet) writeDimensions(buffer *RecordWriter, firstRowIndex uint32, lastRowIndex uint32, firstColumnIndex uint16, lastColumnIndex uint16) {
 	var record uint16 = 0x0200 // Record identifier
 	var length uint16 = 0x000E
 
-	PutVar(buffer, record, length, firstRowIndex, lastRowIndex+1, firstColumnIndex, lastColumnIndex+1, uint16(0x0000))
+	buffer.PutUint16(record, length)
+	buffer.PutUint32(firstRowIndex, lastRowIndex+1)
+	buffer.PutUint16(firstColumnIndex, lastColumnIndex+1, 0x0000)
 }
 
-func (ws *Worksheet) writeBlank(buffer *bytes.Buffer, rowIdx int, columnIdx int, xfIndex int) {
+func (ws *Worksheet) writeBlank(buffer *RecordWriter, rowIdx int, columnIdx int, xfIndex int) {
 	var record uint16 = 0x0201 // Record identifier
 	var length uint16 = 0x0006 // Number of bytes to follow
 
-	PutVar(buffer, record, length)
-	PutVar(buffer, uint16(rowIdx), uint16(columnIdx), uint16(xfIndex))
+	buffer.PutUint16(record, length, uint16(rowIdx), uint16(columnIdx), uint16(xfIndex))
+}
+
+// parseCell detects the cells stored as numbers and returns the number with the XF record formatting it
+func (ws *Worksheet) parseCell(rowIdx int, columnIdx int, value string, workbook *Workbook) (float64, int, bool) {
+	style := ws.cellStyle(rowIdx, columnIdx)
+
+	var num float64
+	var ok bool
+	if ws.NumberLocale.isZero() {
+		num, ok = parseNumber(value)
+	} else {
+		num, ok = ws.NumberLocale.parseNumber(value, true, false)
+	}
+	if ok {
+		if style.NumberFormat == "" {
+			style.NumberFormat = ws.NumberFormat
+		}
+		return num, workbook.AddStyle(style), true
+	}
+
+	if t, hasClock, ok := parseDate(value, ws.DateLayouts); ok {
+		if serial, ok := dateToExcelSerial(t, workbook.Date1904); ok {
+			if style.NumberFormat == "" {
+				style.NumberFormat = ws.dateFormat(hasClock)
+			}
+			return serial, workbook.AddStyle(style), true
+		}
+	}
+
+	return 0, 0, false
+}
+
+// dateFormat returns the format code of the date cells, or of the date and time cells when hasClock is set
+func (ws *Worksheet) dateFormat(hasClock bool) string {
+	if hasClock && ws.DateTimeFormat != "" {
+		return ws.DateTimeFormat
+	}
+	if hasClock {
+		return DefaultDateTimeFormat
+	}
+	if ws.DateFormat != "" {
+		return ws.DateFormat
+	}
+
+	return DefaultDateFormat
+}
+
+// cellStyle ...
+func (ws *Worksheet) cellStyle(rowIdx int, columnIdx int) Style {
+	if style, ok := ws.RowStyles[rowIdx]; ok {
+		return style
+	}
+
+	return ws.ColumnStyles[columnIdx]
+}
+
+// writeRow writes the cells of one row. Adjacent numbers that fit into RK values are packed into MULRK records.
+// The cells that do not match the type of their column are written as text and returned as CellTypeError.
+func (ws *Worksheet) writeRow(buffer *RecordWriter, rowIdx int, row []string, workbook *Workbook) error {
+	rkValues := make([]uint32, 0, len(row))
+	rkXfIndexes := make([]int, 0, len(row))
+	rkFirstColumn := 0
+	var mismatches []error
+
+	flushRk := func() {
+		switch len(rkValues) {
+		case 0:
+			return
+		case 1:
+			ws.writeRk(buffer, rowIdx, rkFirstColumn, rkValues[0], rkXfIndexes[0])
+		default:
+			ws.writeMulRk(buffer, rowIdx, rkFirstColumn, rkValues, rkXfIndexes)
+		}
+		rkValues = rkValues[:0]
+		rkXfIndexes = rkXfIndexes[:0]
+	}
+
+	for columnIdx, cValue := range row {
+		if cValue == "" {
+			flushRk()
+			ws.writeBlank(buffer, rowIdx, columnIdx, workbook.AddStyle(ws.cellStyle(rowIdx, columnIdx)))
+			continue
+		}
+
+		columnType, typed := ws.columnType(rowIdx, columnIdx)
+		var num float64
+		var xfIndex int
+		var ok bool
+		if typed {
+			num, xfIndex, ok = ws.parseTypedCell(rowIdx, columnIdx, cValue, columnType, workbook)
+			if !ok && columnType.Type != CellTypeText {
+				mismatches = append(mismatches, &CellTypeError{Row: rowIdx, Column: columnIdx, Value: cValue, Type: columnType.Type})
+			}
+		} else {
+			num, xfIndex, ok = ws.parseCell(rowIdx, columnIdx, cValue, workbook)
+		}
+		if ok && columnType.Type == CellTypeBool {
+			flushRk()
+			ws.writeBool(buffer, rowIdx, columnIdx, num == 1, xfIndex)
+			continue
+		}
+		if !ok {
+			flushRk()
+			ws.writeString(buffer, rowIdx, columnIdx, cValue, workbook.AddStyle(ws.cellStyle(rowIdx, columnIdx)), workbook.StringCollection)
+			continue
+		}
+
+		rk, ok := encodeRK(num)
+		if !ok {
+			flushRk()
+			ws.writeNumber(buffer, rowIdx, columnIdx, num, xfIndex)
+			continue
+		}
+
+		if len(rkValues) == 0 {
+			rkFirstColumn = columnIdx
+		}
+		rkValues = append(rkValues, rk)
+		rkXfIndexes = append(rkXfIndexes, xfIndex)
+	}
+	flushRk()
+
+	return errors.Join(mismatches...)
 }
 
-func (ws *Worksheet) writeString(buffer *bytes.Buffer, rowIdx int, columnIdx int, cValue string, xfIndex int, stringCollection *StringCollection) {
+func (ws *Worksheet) writeString(buffer *RecordWriter, rowIdx int, columnIdx int, cValue string, xfIndex int, stringCollection *StringCollection) {
 	var record uint16 = 0x00FD // Record identifier
 	var length uint16 = 0x000A // Bytes to follow
 
-	cValue = Utf8toBIFF8UnicodeLong(cValue)
+	strTabVal := stringCollection.AddString(cValue)
+
+	buffer.PutUint16(record, length, uint16(rowIdx), uint16(columnIdx), uint16(xfIndex))
+	buffer.PutUint32(uint32(strTabVal))
+}
+
+func (ws *Worksheet) writeNumber(buffer *RecordWriter, rowIdx int, columnIdx int, num float64, xfIndex int) {
+	var record uint16 = 0x0203 // Record identifier
+	var length uint16 = 0x000E // Number of bytes to follow
 
-	if strTabVal, ok := stringCollection.StringMap[cValue]; ok {
-		PutVar(buffer, record, length)
-		PutVar(buffer, uint16(rowIdx), uint16(columnIdx), uint16(xfIndex), uint32(strTabVal))
+	buffer.PutUint16(record, length, uint16(rowIdx), uint16(columnIdx), uint16(xfIndex))
+	buffer.PutFloat64(num)
+}
+
+func (ws *Worksheet) writeBool(buffer *RecordWriter, rowIdx int, columnIdx int, value bool, xfIndex int) {
+	var record uint16 = 0x0205 // Record identifier
+	var length uint16 = 0x0008 // Number of bytes to follow
+
+	var b uint8
+	if value {
+		b = 1
+	}
+	buffer.PutUint16(record, length, uint16(rowIdx), uint16(columnIdx), uint16(xfIndex))
+	buffer.PutUint8(b, 0x00) // the value is a boolean, not an error code
+}
+
+func (ws *Worksheet) writeRk(buffer *RecordWriter, rowIdx int, columnIdx int, rk uint32, xfIndex int) {
+	var record uint16 = 0x027E // Record identifier
+	var length uint16 = 0x000A // Number of bytes to follow
+
+	buffer.PutUint16(record, length, uint16(rowIdx), uint16(columnIdx), uint16(xfIndex))
+	buffer.PutUint32(rk)
+}
+
+func (ws *Worksheet) writeMulRk(buffer *RecordWriter, rowIdx int, firstColumnIdx int, rkValues []uint32, xfIndexes []int) {
+	var record uint16 = 0x00BD            // Record identifier
+	length := uint16(6 + 6*len(rkValues)) // Number of bytes to follow
+
+	buffer.PutUint16(record, length, uint16(rowIdx), uint16(firstColumnIdx))
+	for i, rk := range rkValues {
+		buffer.PutUint16(uint16(xfIndexes[i]))
+		buffer.PutUint32(rk)
+	}
+	buffer.PutUint16(uint16(firstColumnIdx + len(rkValues) - 1))
+}
+
+func (ws *Worksheet) writeAutoFilterInfo(buffer *RecordWriter) {
+	if ws.AutoFilter == nil {
 		return
 	}
 
-	panic("Something happened wrong")
+	var record uint16 = 0x009D // Record identifier
+	var length uint16 = 0x0002 // Bytes to follow
+
+	cEntries := uint16(ws.AutoFilter.LastColumn - ws.AutoFilter.FirstColumn + 1) // Number of drop-downs
+
+	buffer.PutUint16(record, length, cEntries)
 }
 
-func (ws *Worksheet) writeMsoDrawing(buffer *bytes.Buffer) {
-	// empty
+// writeMsoDrawing writes the drop-down arrows of the auto filter, each one is a shape with its own OBJ record
+func (ws *Worksheet) writeMsoDrawing(buffer *RecordWriter, workbook *Workbook) {
+	if ws.AutoFilter == nil {
+		return
+	}
+
+	var record uint16 = 0x00EC // Record identifier
+
+	filters := ws.AutoFilter.LastColumn - ws.AutoFilter.FirstColumn + 1
+	d := workbook.addDrawing(filters)
+	spid := d.firstShapeId()
+
+	for i := 0; i < filters; i++ {
+		buffer.StartRecord(record)
+
+		if i == 0 {
+			// The drawing and the group shape containing the drop-downs
+			putOfficeArtHeader(buffer, 0xF, 0, 0xF002, uint32(72+96*filters)) // OfficeArtDgContainer
+			putOfficeArtHeader(buffer, 0x0, uint16(d.id), 0xF008, 8)          // OfficeArtFDG
+			buffer.PutUint32(d.shapes, spid+d.shapes-1)
+			putOfficeArtHeader(buffer, 0xF, 0, 0xF003, uint32(48+96*filters)) // OfficeArtSpgrContainer
+			putOfficeArtHeader(buffer, 0xF, 0, 0xF004, 40)                    // OfficeArtSpContainer
+			putOfficeArtHeader(buffer, 0x1, 0, 0xF009, 16)                    // OfficeArtFSPGR
+			buffer.PutUint32(0, 0, 0, 0)
+			putOfficeArtHeader(buffer, 0x2, 0, 0xF00A, 8) // OfficeArtFSP
+			buffer.PutUint32(spid, 0x0005)                // fGroup, fPatriarch
+		}
+
+		col := uint16(ws.AutoFilter.FirstColumn + i)
+		row := uint16(ws.AutoFilter.FirstRow)
+
+		putOfficeArtHeader(buffer, 0xF, 0, 0xF004, 88)   // OfficeArtSpContainer
+		putOfficeArtHeader(buffer, 0x2, 201, 0xF00A, 8)  // OfficeArtFSP, msosptHostControl
+		buffer.PutUint32(spid+uint32(i)+1, 0x0A00)       // fHaveAnchor, fHaveSpt
+		putOfficeArtHeader(buffer, 0x3, 5, 0xF00B, 30)   // OfficeArtFOPT
+		putOfficeArtProperty(buffer, 0x007F, 0x01040104) // Protection
+		putOfficeArtProperty(buffer, 0x00BF, 0x00080008) // Text
+		putOfficeArtProperty(buffer, 0x01BF, 0x00010000) // No fill
+		putOfficeArtProperty(buffer, 0x01FF, 0x00080000) // No line
+		putOfficeArtProperty(buffer, 0x03BF, 0x000A0000) // Not printed
+		putOfficeArtHeader(buffer, 0x0, 0, 0xF010, 18)   // OfficeArtClientAnchorSheet
+		buffer.PutUint16(0x0001, col, 0, row, 0, col+1, 0, row+1, 0)
+		putOfficeArtHeader(buffer, 0x0, 0, 0xF011, 0) // OfficeArtClientData
+
+		buffer.EndRecord()
+
+		ws.writeObjDropDown(buffer, uint16(i+1))
+	}
 }
 
-func (ws *Worksheet) writeWindow2(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeObjDropDown(buffer *RecordWriter, objId uint16) {
+	var record uint16 = 0x005D // Record identifier
+	var length uint16 = 0x0046 // Bytes to follow
+
+	buffer.PutUint16(record, length)
+
+	// ftCmo, common object data
+	var ot uint16 = 0x0014    // Drop-down
+	var grbit uint16 = 0x2101 // Locked, auto filter drop-down, auto fill
+	buffer.PutUint16(0x0015, 0x0012, ot, objId, grbit)
+	buffer.PutUint32(0, 0, 0)
+
+	// ftSbs, scroll bar data is not used
+	buffer.PutUint16(0x000C, 0x0014)
+	buffer.PutBytes(make([]byte, 20))
+
+	// ftLbsData, list box data
+	var lbsFlags uint16 = 0x0301      // fUseCB, auto filter
+	var dropDownFlags uint16 = 0x0002 // Simple drop-down
+	buffer.PutUint16(0x0013, 0x0010)
+	buffer.PutUint32(0)
+	buffer.PutUint16(0, lbsFlags, 0, dropDownFlags, 20, 18)
+
+	// ftEnd
+	buffer.PutUint16(0x0000, 0x0000)
+}
+
+func (ws *Worksheet) writeWindow2(buffer *RecordWriter) {
 	var record uint16 = 0x023E // Record identifier
 	var length uint16 = 0x0012
 
@@ -464,15 +770,18 @@ func (ws *Worksheet) writeWindow2(buffer *bytes.Buffer) {
 	var colLeft uint16 = 0x0000 // Leftmost column visible in window
 
 	// The options flags that comprise $grbit
-	var fDspFmla uint16 = 0       // 0 - bit
-	var fDspGrid uint16 = 1       // 1
-	var fDspRwCol uint16 = 1      // 2
-	var fFrozen uint16 = 0        // 3
-	var fDspZeros uint16 = 1      // 4
-	var fDefaultHdr uint16 = 1    // 5
-	var fArabic uint16 = 0        // 6
-	var fDspGuts uint16 = 1       // 7
-	var fFrozenNoSplit uint16 = 0 // 0 - bit
+	var fDspFmla uint16 = 0  // 0 - bit
+	var fDspGrid uint16 = 1  // 1
+	var fDspRwCol uint16 = 1 // 2
+	var fFrozen uint16 = 0   // 3
+	if ws.isFrozen() {
+		fFrozen = 1
+	}
+	var fDspZeros uint16 = 1   // 4
+	var fDefaultHdr uint16 = 1 // 5
+	var fArabic uint16 = 0     // 6
+	var fDspGuts uint16 = 1    // 7
+	fFrozenNoSplit := fFrozen  // 0 - bit
 	// no support in PhpSpreadsheet for selected sheet, therefore sheet is only selected if it is the active sheet
 	var fSelected uint16 = 1
 	var fPaged uint16 = 1 // 2
@@ -491,17 +800,17 @@ func (ws *Worksheet) writeWindow2(buffer *bytes.Buffer) {
 	grbit |= fPaged << 10
 	grbit |= fPageBreakPreview << 11
 
-	PutVar(buffer, record, length)
-	PutVar(buffer, grbit, rwTop, colLeft)
+	buffer.PutUint16(record, length, grbit, rwTop, colLeft)
 
 	var rgbHdr uint16 = 0x0040 // Row/column heading and gridline color index
 	var zoom_factor_page_break uint16 = 0
 	var zoom_factor_normal uint16 = 100
 
-	PutVar(buffer, rgbHdr, uint16(0x0000), zoom_factor_page_break, zoom_factor_normal, uint32(0x00000000))
+	buffer.PutUint16(rgbHdr, 0x0000, zoom_factor_page_break, zoom_factor_normal)
+	buffer.PutUint32(0x00000000)
 }
 
-func (ws *Worksheet) writePageLayoutView(buffer *bytes.Buffer) {
+func (ws *Worksheet) writePageLayoutView(buffer *RecordWriter) {
 	var record uint16 = 0x088B // Record identifier
 	var length uint16 = 0x0010 // Bytes to follow
 
@@ -517,35 +826,89 @@ func (ws *Worksheet) writePageLayoutView(buffer *bytes.Buffer) {
 	grbit |= fRulerVisible << 1
 	grbit |= fWhitespaceHidden << 3
 
-	PutVar(buffer, record, length)
-	PutVar(buffer, rt, grbitFrt, uint32(0x00000000), uint32(0x00000000), wScalvePLV, grbit)
+	buffer.PutUint16(record, length, rt, grbitFrt)
+	buffer.PutUint32(0x00000000, 0x00000000)
+	buffer.PutUint16(wScalvePLV, grbit)
 }
 
-func (ws *Worksheet) writeZoom(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeZoom(buffer *RecordWriter) {
 	// empty
 }
 
-func (ws *Worksheet) writeSelection(buffer *bytes.Buffer) {
+func (ws *Worksheet) writePanes(buffer *RecordWriter) {
+	if !ws.isFrozen() {
+		return
+	}
+
+	var record uint16 = 0x0041 // Record identifier
+	var length uint16 = 0x000A // Number of bytes to follow
+
+	x := uint16(ws.FrozenColumns) // Vertical split position
+	y := uint16(ws.FrozenRows)    // Horizontal split position
+	rwTop := y                    // Top row visible in the bottom pane
+	colLeft := x                  // Leftmost column visible in the right pane
+
+	buffer.PutUint16(record, length, x, y, rwTop, colLeft)
+	buffer.PutUint8(ws.activePane(), 0)
+}
+
+// isFrozen ...
+func (ws *Worksheet) isFrozen() bool {
+	return ws.FrozenRows > 0 || ws.FrozenColumns > 0
+}
+
+// activePane returns the pane with the cursor: the scrollable one
+func (ws *Worksheet) activePane() uint8 {
+	switch {
+	case ws.FrozenColumns > 0 && ws.FrozenRows > 0:
+		return 0 // Bottom right
+	case ws.FrozenColumns > 0:
+		return 1 // Top right
+	case ws.FrozenRows > 0:
+		return 2 // Bottom left
+	}
+
+	return 3 // Top left
+}
+
+func (ws *Worksheet) writeSelection(buffer *RecordWriter) {
+	rows := uint16(ws.FrozenRows)
+	columns := uint16(ws.FrozenColumns)
+
+	// every pane has its own selection, the cursor is at the first cell of the pane
+	if rows > 0 && columns > 0 {
+		ws.writePaneSelection(buffer, 1, 0, columns) // Top right
+		ws.writePaneSelection(buffer, 2, rows, 0)    // Bottom left
+	}
+	ws.writePaneSelection(buffer, ws.activePane(), rows, columns)
+}
+
+func (ws *Worksheet) writePaneSelection(buffer *RecordWriter, pnn uint8, rwAct uint16, colAct uint16) {
 	var record uint16 = 0x001D // Record identifier
 	var length uint16 = 0x000F // Number of bytes to follow
 
-	PutVar(buffer, record, length)
-	PutVar(buffer, uint8(3), uint16(0), uint16(0), uint16(0), uint16(1), uint16(0), uint16(0), uint8(0), uint8(0))
+	var irefAct uint16 = 0 // Active cell ref
+	var cref uint16 = 1    // Number of refs
+
+	buffer.PutUint16(record, length)
+	buffer.PutUint8(pnn)
+	buffer.PutUint16(rwAct, colAct, irefAct, cref, rwAct, rwAct)
+	buffer.PutUint8(uint8(colAct), uint8(colAct))
 }
 
-func (ws *Worksheet) writeMergedCells(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeMergedCells(buffer *RecordWriter) {
 	// empty
 }
 
-func (ws *Worksheet) writeDataValidity(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeDataValidity(buffer *RecordWriter) {
 	// empty
 }
 
-func (ws *Worksheet) writeSheetLayout(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeSheetLayout(buffer *RecordWriter) {
 	// empty
 }
 
-func (ws *Worksheet) writeSheetProtection(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeSheetProtection(buffer *RecordWriter) {
 	// record identifier
 	var record uint16 = 0x0867
 	var length uint16 = 23
@@ -553,17 +916,20 @@ func (ws *Worksheet) writeSheetProtection(buffer *bytes.Buffer) {
 	// prepare options
 	var options uint16 = 32767
 
-	PutVar(buffer, record, length)
-	PutVar(buffer, uint16(0x0867), uint32(0x0000), uint32(0x0000), uint8(0x00), uint32(0x01000200), uint32(0xFFFFFFFF), options, uint16(0x0000))
+	buffer.PutUint16(record, length, 0x0867)
+	buffer.PutUint32(0x0000, 0x0000)
+	buffer.PutUint8(0x00)
+	buffer.PutUint32(0x01000200, 0xFFFFFFFF)
+	buffer.PutUint16(options, 0x0000)
 }
 
-func (ws *Worksheet) writeRangeProtection(buffer *bytes.Buffer) {
+func (ws *Worksheet) writeRangeProtection(buffer *RecordWriter) {
 	// empty
 }
 
-func (ws *Worksheet) storeEof(buffer *bytes.Buffer) {
+func (ws *Worksheet) storeEof(buffer *RecordWriter) {
 	var record uint16 = 0x000A // Record identifier
 	var length uint16 = 0x0000 // Number of bytes to follow
 
-	PutVar(buffer, record, length)
+	buffer.PutUint16(record, length)
 }
@@ -0,0 +1,139 @@
+package goxls
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+)
+
+// MaxRows is the number of rows a BIFF8 worksheet can hold
+const MaxRows = 65536
+
+// MaxColumns is the number of columns a BIFF8 worksheet can hold
+const MaxColumns = 256
+
+// MaxWorksheets is the number of worksheets a BIFF8 workbook can hold
+const MaxWorksheets = 255
+
+// WorksheetStream writes a worksheet row by row. The cell records are spilled to a temporary file
+// as the rows arrive, so only the shared strings and the styles of the workbook stay in memory.
+// The settings of the embedded Worksheet, except Grid, may be changed until Finish is called.
+type WorksheetStream struct {
+	Worksheet
+
+	workbook  *Workbook
+	file      *os.File
+	cells     *bufio.Writer
+	cellsSize int64
+	rowBuf    *RecordWriter
+	rows      int
+	maxColIdx int
+	head      []byte
+	tail      []byte
+}
+
+// NewWorksheetStream creates the temporary file of the worksheet cells in the directory dir,
+// the default directory for temporary files is used when dir is empty
+func NewWorksheetStream(ws Worksheet, workbook *Workbook, dir string) (*WorksheetStream, error) {
+	f, err := os.CreateTemp(dir, "goxls-worksheet-*")
+	if err != nil {
+		return nil, err
+	}
+
+	return &WorksheetStream{
+		Worksheet: ws,
+		workbook:  workbook,
+		file:      f,
+		cells:     bufio.NewWriter(f),
+		rowBuf:    NewRecordWriter(4096),
+	}, nil
+}
+
+// WriteRow appends a row to the worksheet. The cells that do not match the type of their column are written
+// as text, they are returned as CellTypeError joined together after the row is written.
+func (s *WorksheetStream) WriteRow(row []string) error {
+	if s.head != nil {
+		return errors.New("worksheet is finished")
+	}
+	if err := checkRow(s.rows, row); err != nil {
+		return err
+	}
+
+	s.rowBuf.Reset()
+	mismatches := s.writeRow(s.rowBuf, s.rows, row, s.workbook)
+	n, err := s.cells.Write(s.rowBuf.Bytes())
+	s.cellsSize += int64(n)
+	if err != nil {
+		return err
+	}
+
+	s.rows++
+	s.maxColIdx = max(s.maxColIdx, len(row)-1)
+
+	return mismatches
+}
+
+// SkipRows leaves the next n rows empty
+func (s *WorksheetStream) SkipRows(n int) error {
+	if s.rows+n > MaxRows {
+		return ErrTooManyRows
+	}
+	s.rows += n
+
+	return nil
+}
+
+// Rows returns the number of rows written
+func (s *WorksheetStream) Rows() int {
+	return s.rows
+}
+
+// Columns returns the number of columns of the widest row
+func (s *WorksheetStream) Columns() int {
+	if s.rows == 0 {
+		return 0
+	}
+
+	return s.maxColIdx + 1
+}
+
+// Finish writes the records before and after the cells. Call it after the last row, in the order of
+// the worksheets in the workbook and before the workbook globals are written.
+func (s *WorksheetStream) Finish() error {
+	if err := s.cells.Flush(); err != nil {
+		return err
+	}
+
+	head, tail := NewRecordWriter(4096), NewRecordWriter(4096)
+	s.writeHead(head, s.workbook, s.rows, s.maxColIdx)
+	s.writeTail(tail, s.workbook)
+	s.head, s.tail = head.Bytes(), tail.Bytes()
+
+	return nil
+}
+
+// Size returns the length of the worksheet substream, it is known after Finish
+func (s *WorksheetStream) Size() int {
+	return len(s.head) + int(s.cellsSize) + len(s.tail)
+}
+
+// Reader returns the worksheet substream, it is available after Finish
+func (s *WorksheetStream) Reader() io.Reader {
+	return io.MultiReader(
+		bytes.NewReader(s.head),
+		io.NewSectionReader(s.file, 0, s.cellsSize),
+		bytes.NewReader(s.tail),
+	)
+}
+
+// Close removes the temporary file
+func (s *WorksheetStream) Close() error {
+	closeErr := s.file.Close()
+	if err := os.Remove(s.file.Name()); err != nil {
+		return err
+	}
+
+	return closeErr
+}